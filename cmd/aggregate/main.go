@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lordalek/dat650-project/metrics"
+)
+
+// metrics of a miner that are aggregated, as named in the csv output; files in the table format only have the first four
+var columns = []string{"power", "rewards_gained", "main_blocks_created", "uncle_blocks_created", "power_share", "reward_share"}
+
+// names of the metrics in the jsonl and json output
+var jsonColumns = map[string]string{
+	"Power":       "power",
+	"Rewards":     "rewards_gained",
+	"MainBlocks":  "main_blocks_created",
+	"UncleBlocks": "uncle_blocks_created",
+	"PowerShare":  "power_share",
+	"RewardShare": "reward_share",
+}
+
+// results of every miner in a results file, grouped by run.
+type fileResults struct {
+	columns []string                              //metrics found in the file, in the order of columns
+	ids     []string                              //miners in order of first appearance
+	runs    []int                                 //runs in order of first appearance
+	values  map[int]map[string]map[string]float64 //run -> minerID -> metric -> value
+	seen    map[string]bool
+}
+
+func newFileResults() *fileResults {
+	return &fileResults{values: make(map[int]map[string]map[string]float64), seen: make(map[string]bool)}
+}
+
+func (r *fileResults) add(run int, id string, values map[string]float64) {
+	if _, found := r.values[run]; !found {
+		r.runs = append(r.runs, run)
+		r.values[run] = make(map[string]map[string]float64)
+	}
+	if !r.seen[id] {
+		r.seen[id] = true
+		r.ids = append(r.ids, id)
+	}
+	r.values[run][id] = values
+	if len(r.columns) == 0 {
+		for _, c := range columns {
+			if _, found := values[c]; found {
+				r.columns = append(r.columns, c)
+			}
+		}
+	}
+}
+
+// the metric of a miner in every run it takes part in, in run order
+func (r *fileResults) series(id, column string) []float64 {
+	x := []float64{}
+	for _, run := range r.runs {
+		if v, found := r.values[run][id][column]; found {
+			x = append(x, v)
+		}
+	}
+	return x
+}
+
+// reads a results file in any of the simulator's formats, telling them apart by the first line:
+// the table format has a minerID header for every run, which is counted as the run id;
+// the csv format has a run column; jsonl has a record per line, json a single document holding the runs' records.
+// a file without any miner's results is an error, so no empty summary is written.
+func readResults(path string) (*fileResults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r *fileResults
+	switch text := string(data); {
+	case strings.HasPrefix(text, "minerID,"):
+		r, err = readTable(text)
+	case strings.HasPrefix(text, "run,"):
+		r, err = readCSV(text)
+	case strings.HasPrefix(text, "{"):
+		r, err = readJSON(text)
+	default:
+		err = fmt.Errorf("not a results file in the table, csv, jsonl or json format")
+	}
+	if err == nil && len(r.ids) == 0 {
+		err = fmt.Errorf("no results of any miner")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
+
+func readTable(text string) (*fileResults, error) {
+	r := newFileResults()
+	run := -1
+	//only rows under a minerID header hold mining results.
+	inMiners := true
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		row := strings.Split(line, ",")
+		if "minerID" == row[0] || "adversaryID" == row[0] {
+			inMiners = "minerID" == row[0]
+			if inMiners {
+				run++
+			}
+			continue
+		}
+		if !inMiners || len(line) == 0 {
+			continue
+		}
+		if len(row) != 5 {
+			return nil, fmt.Errorf("run %d: row %q does not have 5 fields", run, line)
+		}
+		values := make(map[string]float64)
+		for i, c := range columns[:4] {
+			v, err := strconv.ParseFloat(row[i+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("run %d: %s of %s: %v", run, c, row[0], err)
+			}
+			values[c] = v
+		}
+		r.add(run, row[0], values)
+	}
+	return r, scanner.Err()
+}
+
+func readCSV(text string) (*fileResults, error) {
+	rows, err := csv.NewReader(strings.NewReader(text)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for i, name := range rows[0] {
+		index[name] = i
+	}
+	if _, found := index["minerID"]; !found {
+		return nil, fmt.Errorf("no minerID column")
+	}
+	r := newFileResults()
+	for _, row := range rows[1:] {
+		run, err := strconv.Atoi(row[index["run"]])
+		if err != nil {
+			return nil, fmt.Errorf("run %q: %v", row[index["run"]], err)
+		}
+		values := make(map[string]float64)
+		for _, c := range columns {
+			i, found := index[c]
+			if !found {
+				continue
+			}
+			if values[c], err = strconv.ParseFloat(row[i], 64); err != nil {
+				return nil, fmt.Errorf("run %d: %s of %s: %v", run, c, row[index["minerID"]], err)
+			}
+		}
+		r.add(run, row[index["minerID"]], values)
+	}
+	return r, nil
+}
+
+// reads either format starting with a json object: the json document has Runs, a jsonl record has none.
+func readJSON(text string) (*fileResults, error) {
+	first := make(map[string]json.RawMessage)
+	if err := json.NewDecoder(strings.NewReader(text)).Decode(&first); err != nil {
+		return nil, err
+	}
+	if _, found := first["Runs"]; !found {
+		return readJSONL(text)
+	}
+	doc := struct {
+		Runs []struct {
+			Records []map[string]interface{}
+		}
+	}{}
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return nil, err
+	}
+	r := newFileResults()
+	for _, run := range doc.Runs {
+		for _, record := range run.Records {
+			r.addRecord(record)
+		}
+	}
+	return r, nil
+}
+
+func readJSONL(text string) (*fileResults, error) {
+	r := newFileResults()
+	dec := json.NewDecoder(strings.NewReader(text))
+	for {
+		record := make(map[string]interface{})
+		if err := dec.Decode(&record); err == io.EOF {
+			return r, nil
+		} else if err != nil {
+			return nil, err
+		}
+		r.addRecord(record)
+	}
+}
+
+// adds a record of the json formats, see sim.Record.
+func (r *fileResults) addRecord(record map[string]interface{}) {
+	run, _ := record["Run"].(float64)
+	id, _ := record["MinerID"].(string)
+	values := make(map[string]float64)
+	for field, c := range jsonColumns {
+		if v, found := record[field].(float64); found {
+			values[c] = v
+		}
+	}
+	r.add(int(run), id, values)
+}
+
+// expands the arguments into results files, each listed once in order of the arguments.
+func inputPaths(args []string) ([]string, error) {
+	seen := make(map[string]bool)
+	paths := []string{}
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no results files found", arg)
+		}
+		for _, p := range matches {
+			if p = filepath.Clean(p); !seen[p] {
+				seen[p] = true
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// summarizes every results file given, writing a row per miner and metric to <out>/<file name>.summary.csv;
+// summaries are rewritten, not appended to, when aggregating again.
+// with -compare a b, instead tests for every miner and metric whether its mean differs between the two results files.
+//
+//	usage: aggregate [-out dir] file|glob...
+//	       aggregate -compare a.csv b.csv
+func main() {
+	out := flag.String("out", ".", "directory to write the summaries to")
+	compare := flag.Bool("compare", false, "Welch's t-test between the two results files given")
+	flag.Parse()
+	if *compare {
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err := welch(flag.Arg(0), flag.Arg(1)); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	paths, err := inputPaths(flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	//every file is read before any summary is written, so a bad file leaves no partial output.
+	names := make(map[string]string) //summary file -> results file
+	summaries := []string{}
+	results := []*fileResults{}
+	for _, p := range paths {
+		summary := filepath.Join(*out, filepath.Base(p)+".summary.csv")
+		if other, found := names[summary]; found {
+			log.Fatalf("%s and %s would write the same summary %s", other, p, summary)
+		}
+		names[summary] = p
+		r, err := readResults(p)
+		if err != nil {
+			log.Fatal(err)
+		}
+		summaries = append(summaries, summary)
+		results = append(results, r)
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+	for i, summary := range summaries {
+		if err := writeSummary(summary, results[i]); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func writeSummary(path string, results *fileResults) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	fmt.Fprintln(w, "minerID,metric,n,mean,sd,se,ci_low,ci_high,min,max,median")
+	for _, key := range results.ids {
+		for _, column := range results.columns {
+			s := metrics.Summarize(results.series(key, column))
+			fmt.Fprintf(w, "%s,%s,%d,%f,%f,%f,%f,%f,%f,%f,%f\n", key, column, s.N,
+				s.Mean, s.SD, s.SE, s.CILow, s.CIHigh, s.Min, s.Max, s.Median)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Welch's t-test per miner and metric for miners in both files, written to stdout.
+func welch(pathA, pathB string) error {
+	a, err := readResults(pathA)
+	if err != nil {
+		return err
+	}
+	b, err := readResults(pathB)
+	if err != nil {
+		return err
+	}
+	fmt.Println("minerID,metric,n_a,mean_a,n_b,mean_b,t,df,p")
+	for _, key := range a.ids {
+		for _, column := range a.columns {
+			x, y := a.series(key, column), b.series(key, column)
+			if len(y) == 0 {
+				continue
+			}
+			w := metrics.WelchTest(x, y)
+			sx, sy := metrics.Summarize(x), metrics.Summarize(y)
+			fmt.Printf("%s,%s,%d,%f,%d,%f,%f,%f,%f\n", key, column, sx.N, sx.Mean, sy.N, sy.Mean, w.T, w.DF, w.P)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// writes a config file for every combination of parameters in a sweep spec, see Spec.
+//
+//	usage: gensweep [-out dir] spec.json
+func main() {
+	savePath := flag.String("out", "./config_uncleOptions/", "directory to write the configs to")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	spec, err := readSpec(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	configs, err := spec.expand()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*savePath, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, c := range configs {
+		jconf, err := json.Marshal(c.conf)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(*savePath, c.name), jconf, 0644)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("wrote %d configs to %s\n", len(configs), *savePath)
+}
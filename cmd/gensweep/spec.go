@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lordalek/dat650-project/sim"
+)
+
+// Spec describes a parameter sweep: every combination of the parameters' values, applied on top of Base,
+// is one config, unless it matches an exclusion rule.
+type Spec struct {
+	Base       json.RawMessage //config the parameters are applied to
+	Parameters []Parameter     //varied in order, the first one slowest
+	Exclude    [][]Condition   //a combination is dropped if it meets all conditions of any rule
+}
+
+// Parameter is a config field given as a list of values or a range,
+// or a number of fields given as a list of tuples that are varied together.
+// a parameter with conditions is only varied for combinations meeting them, the others keep the base value.
+type Parameter struct {
+	Name   string
+	Values []interface{}
+	Range  *Range
+
+	Names  []string
+	Tuples [][]interface{}
+
+	When []Condition
+}
+
+// Range is the numbers From, From+Step, ... up to and including To.
+type Range struct {
+	From float64
+	To   float64
+	Step float64
+}
+
+// Condition compares a config field to a value; Op is one of ==, !=, <, <=, > and >=.
+// only == and != apply to values that are not numbers.
+type Condition struct {
+	Param string
+	Op    string
+	Value interface{}
+}
+
+// a config of the sweep and the name of the file to write it to
+type sweepConfig struct {
+	name string
+	conf sim.Config
+}
+
+func readSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	spec := &Spec{}
+	if err := dec.Decode(spec); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return spec, nil
+}
+
+// expands the spec into its configs, in order of the parameters.
+// the file name of a config lists the parameters that were varied for it with their values, so it only
+// depends on the combination, not on the position of the config in the sweep.
+func (s *Spec) expand() ([]sweepConfig, error) {
+	base := sim.Config{}
+	if len(s.Base) > 0 {
+		if err := decodeConfig(s.Base, &base); err != nil {
+			return nil, fmt.Errorf("base: %v", err)
+		}
+	}
+	//configs are put together as maps of field names to values, fields left out of Base are zero.
+	data, _ := json.Marshal(base)
+	fields := make(map[string]interface{})
+	json.Unmarshal(data, &fields)
+
+	columns := make([][][]interface{}, len(s.Parameters)) //per parameter the values of its fields
+	names := make([][]string, len(s.Parameters))
+	varied := make(map[string]bool)
+	for i, p := range s.Parameters {
+		var err error
+		names[i], columns[i], err = p.values()
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %v", i+1, err)
+		}
+		for _, c := range p.When {
+			if err := c.check(fields); err != nil {
+				return nil, fmt.Errorf("parameter %d: %v", i+1, err)
+			}
+			if !varied[c.Param] && isParameter(s.Parameters[i:], c.Param) {
+				return nil, fmt.Errorf("parameter %d: condition on %s, which is not varied before", i+1, c.Param)
+			}
+		}
+		for _, n := range names[i] {
+			if _, found := fields[n]; !found {
+				return nil, fmt.Errorf("parameter %d: unknown config field %q", i+1, n)
+			}
+			if varied[n] {
+				return nil, fmt.Errorf("parameter %d: %s is varied twice", i+1, n)
+			}
+			varied[n] = true
+		}
+	}
+	for i, rule := range s.Exclude {
+		if len(rule) == 0 {
+			return nil, fmt.Errorf("exclusion rule %d: no conditions", i+1)
+		}
+		for _, c := range rule {
+			if err := c.check(fields); err != nil {
+				return nil, fmt.Errorf("exclusion rule %d: %v", i+1, err)
+			}
+		}
+	}
+
+	configs := []sweepConfig{}
+	seen := make(map[string]bool)
+	var combine func(i int, label []string) error
+	combine = func(i int, label []string) error {
+		if i == len(s.Parameters) {
+			for _, rule := range s.Exclude {
+				if meets(fields, rule) {
+					return nil
+				}
+			}
+			name := "config"
+			if len(label) > 0 {
+				name += "_" + strings.Join(label, "_")
+			}
+			if seen[name] {
+				return nil
+			}
+			seen[name] = true
+			data, _ := json.Marshal(fields)
+			conf := sim.Config{}
+			if err := decodeConfig(data, &conf); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			//validated as the simulator will load it, without writing the defaults into the file.
+			check := conf
+			check.SetDefaults()
+			if err := check.Validate(); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			configs = append(configs, sweepConfig{name: name + ".json", conf: conf})
+			return nil
+		}
+		if !meets(fields, s.Parameters[i].When) {
+			return combine(i+1, label)
+		}
+		for k, tuple := range columns[i] {
+			old := make([]interface{}, len(tuple))
+			next := append([]string{}, label...)
+			for j, n := range names[i] {
+				old[j] = fields[n]
+				fields[n] = tuple[j]
+				next = append(next, n, nameValue(tuple[j], k))
+			}
+			err := combine(i+1, next)
+			for j, n := range names[i] {
+				fields[n] = old[j]
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := combine(0, nil); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// the fields of the parameter, and the values they take together.
+func (p Parameter) values() ([]string, [][]interface{}, error) {
+	given := 0
+	for _, set := range []bool{p.Values != nil, p.Range != nil, p.Tuples != nil} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, nil, fmt.Errorf("give exactly one of Values, Range and Tuples")
+	}
+	if p.Tuples != nil {
+		if p.Name != "" || len(p.Names) == 0 {
+			return nil, nil, fmt.Errorf("tuples need Names, not Name")
+		}
+		for _, t := range p.Tuples {
+			if len(t) != len(p.Names) {
+				return nil, nil, fmt.Errorf("tuple %v does not have a value for each of %v", t, p.Names)
+			}
+		}
+		return p.Names, p.Tuples, nil
+	}
+	if p.Name == "" || len(p.Names) > 0 {
+		return nil, nil, fmt.Errorf("values and ranges need a Name, not Names")
+	}
+	values := p.Values
+	if p.Range != nil {
+		r := p.Range
+		if r.Step <= 0 || r.To < r.From {
+			return nil, nil, fmt.Errorf("%s: empty range from %v to %v in steps of %v", p.Name, r.From, r.To, r.Step)
+		}
+		//values are computed from the start each time and rounded, so steps like 0.1 add up to the expected numbers.
+		for k := 0; ; k++ {
+			v := math.Round((r.From+float64(k)*r.Step)*1e9) / 1e9
+			if v > r.To+1e-9 {
+				break
+			}
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil, fmt.Errorf("%s: no values", p.Name)
+	}
+	tuples := make([][]interface{}, len(values))
+	for i, v := range values {
+		tuples[i] = []interface{}{v}
+	}
+	return []string{p.Name}, tuples, nil
+}
+
+func isParameter(params []Parameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+		for _, n := range p.Names {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c Condition) check(fields map[string]interface{}) error {
+	if _, found := fields[c.Param]; !found {
+		return fmt.Errorf("condition on unknown config field %q", c.Param)
+	}
+	switch c.Op {
+	case "==", "!=":
+	case "<", "<=", ">", ">=":
+		if _, ok := c.Value.(float64); !ok {
+			return fmt.Errorf("%s %s %v: only numbers can be ordered", c.Param, c.Op, c.Value)
+		}
+	default:
+		return fmt.Errorf("unknown operator %q", c.Op)
+	}
+	return nil
+}
+
+// true if the config meets all conditions
+func meets(fields map[string]interface{}, conditions []Condition) bool {
+	for _, c := range conditions {
+		v := fields[c.Param]
+		a, aNum := v.(float64)
+		b, bNum := c.Value.(float64)
+		var ok bool
+		switch c.Op {
+		case "==":
+			ok = formatValue(v) == formatValue(c.Value)
+		case "!=":
+			ok = formatValue(v) != formatValue(c.Value)
+		case "<":
+			ok = aNum && bNum && a < b
+		case "<=":
+			ok = aNum && bNum && a <= b
+		case ">":
+			ok = aNum && bNum && a > b
+		case ">=":
+			ok = aNum && bNum && a >= b
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// numbers in their shortest form, strings as they are, anything else as json.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// a value as part of a file name: numbers and strings, with path separators and spaces replaced,
+// or for lists and objects the number of the value in the parameter's list.
+func nameValue(v interface{}, k int) string {
+	switch v.(type) {
+	case float64, string, bool:
+		return strings.NewReplacer("/", "-", "\\", "-", " ", "-").Replace(formatValue(v))
+	}
+	return strconv.Itoa(k + 1)
+}
+
+// decodes a config, rejecting fields the config does not have.
+func decodeConfig(data []byte, conf *sim.Config) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(conf)
+}
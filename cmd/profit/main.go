@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/lordalek/dat650-project/sim"
+)
+
+// reports how much the adversaries of a config earn compared to their fair share, per run and over all runs.
+// with -threshold, also bisects the adversary's mining power for the least power at which selfish mining pays.
+//
+//	usage: profit [-threshold] [-tolerance t] config.json
+func main() {
+	threshold := flag.Bool("threshold", false, "bisect the adversary's mining power for the profitability threshold")
+	tolerance := flag.Float64("tolerance", 0.01, "width of the power interval at which the bisection stops")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	conf, err := sim.LoadConfig(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+
+	results, err := sim.SimulateRuns(conf, runtime.NumCPU())
+	if err != nil {
+		fail(err)
+	}
+	fmt.Println("run,adversaryID,rewards,relative_revenue,fair_share,ratio,revenue_per_time")
+	for _, r := range results {
+		for _, p := range r.Profits() {
+			printProfit(fmt.Sprint(p.Run), p)
+		}
+	}
+	for _, p := range sim.PooledProfits(results) {
+		printProfit("all", p)
+	}
+
+	if *threshold {
+		t, err := sim.ProfitabilityThreshold(conf, *tolerance, runtime.NumCPU())
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println("threshold_power,adversaryID,relative_revenue,fair_share,ratio,steps")
+		fmt.Printf("%f,%s,%f,%f,%f,%d\n", t.Power, t.Profit.ID, t.Profit.RelativeRevenue, t.Profit.FairShare, t.Profit.Ratio, t.Steps)
+	}
+}
+
+func printProfit(run string, p sim.Profit) {
+	fmt.Printf("%s,%s,%f,%f,%f,%f,%f\n", run, p.ID, p.Rewards, p.RelativeRevenue, p.FairShare, p.Ratio, p.RevenuePerTime)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
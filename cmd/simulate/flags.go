@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/lordalek/dat650-project/sim"
+)
+
+// fieldFlag sets a config field from the command line, the flag being the field's name in kebab case,
+// e.g. -max-uncles for MaxUncles. lists such as -adversaries take the json of the field.
+type fieldFlag struct {
+	field string
+	typ   reflect.Type
+	value string
+	set   bool
+}
+
+func (f *fieldFlag) String() string {
+	return f.value
+}
+
+// the value is checked right away, so a malformed flag is reported by the flag package.
+func (f *fieldFlag) Set(s string) error {
+	if _, err := f.json(s); err != nil {
+		return err
+	}
+	f.value, f.set = s, true
+	return nil
+}
+
+// the value as the json of the field
+func (f *fieldFlag) json(s string) (json.RawMessage, error) {
+	data := []byte(s)
+	if f.typ.Kind() == reflect.String {
+		data, _ = json.Marshal(s)
+	}
+	if err := json.Unmarshal(data, reflect.New(f.typ).Interface()); err != nil {
+		return nil, fmt.Errorf("not a valid %s", f.typ)
+	}
+	return data, nil
+}
+
+// adds a flag for every config field to fs.
+func configFlags(fs *flag.FlagSet) []*fieldFlag {
+	flags := []*fieldFlag{}
+	t := reflect.TypeOf(sim.Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		f := &fieldFlag{field: field.Name, typ: field.Type}
+		usage := fmt.Sprintf("set the config field %s", field.Name)
+		if field.Type.Kind() == reflect.Slice {
+			usage += ", as json"
+		}
+		fs.Var(f, kebab(field.Name), usage)
+		flags = append(flags, f)
+	}
+	return flags
+}
+
+// overrides the fields of conf whose flags were set.
+func applyFlags(conf *sim.Config, flags []*fieldFlag) error {
+	overrides := make(map[string]json.RawMessage)
+	for _, f := range flags {
+		if f.set {
+			overrides[f.field], _ = f.json(f.value)
+		}
+	}
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, conf)
+}
+
+// MaxUncles -> max-uncles
+func kebab(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/lordalek/dat650-project/sim"
+)
+
+// runs the simulation described by a config file, by flags for the config fields, or by a config file
+// with some of its fields overridden by flags.
+// fields left out get the defaults of sim.Config; selfish miners following the default delay strategy
+// also need -selfish-delay.
+//
+//	usage: simulate [-out file] [-format csv|table|jsonl|json] [-runs n] [-max-uncles n] ... [config.json]
+func main() {
+	out := flag.String("out", "", "file to write the results to, default stdout")
+	format := flag.String("format", sim.FORMAT_CSV, "format of the results: csv, table, jsonl or json")
+	flags := configFlags(flag.CommandLine)
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	//read config file if given, then apply the flags on top of it.
+	conf := sim.Config{}
+	if flag.NArg() == 1 {
+		var err error
+		conf, err = sim.ReadConfig(flag.Arg(0))
+		if err != nil {
+			fail(err)
+		}
+	}
+	if err := applyFlags(&conf, flags); err != nil {
+		fail(err)
+	}
+	conf.SetDefaults()
+	if err := conf.Validate(); err != nil {
+		fail(err)
+	}
+	//an unknown format is reported before simulating.
+	if err := sim.WriteResults(io.Discard, *format, conf, nil); err != nil {
+		fail(err)
+	}
+
+	//perform a number of simulations, number specified in config, in parallel.
+	results, err := sim.SimulateRuns(conf, runtime.NumCPU())
+	if err != nil {
+		fail(err)
+	}
+	//print results to stdout, or write them to the file given.
+	if *out != "" {
+		err = sim.WriteResultsFile(*out, *format, conf, results)
+	} else {
+		err = sim.WriteResults(os.Stdout, *format, conf, results)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/lordalek/dat650-project/sim"
+)
+
+// runs every config given as a directory of json files or a glob, a number of configs at a time,
+// and writes the results of each to <out>/<config file name>.<format>, in one of the formats of the simulate command.
+// configs whose results file already exists are skipped, so an interrupted sweep can be resumed.
+// <out>/index.tsv, tab-separated, maps the parameters of every config to its results file;
+// unlike the results, it does not end in .csv, so a glob such as <out>/*.csv leaves it out.
+//
+//	usage: sweep [-out dir] [-j n] [-format csv|table|jsonl|json] dir|glob...
+func main() {
+	out := flag.String("out", "./results", "directory to write the results to")
+	jobs := flag.Int("j", runtime.NumCPU(), "number of configs to simulate at a time")
+	format := flag.String("format", sim.FORMAT_CSV, "format of the results: csv, table, jsonl or json")
+	flag.Parse()
+	if flag.NArg() == 0 || *jobs < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := sim.WriteResults(io.Discard, *format, sim.Config{}, nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	paths, err := configPaths(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	entries := make([]entry, len(paths))
+	todo := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range todo {
+				entries[i] = sweep(paths[i], *out, *format)
+				fmt.Fprintf(os.Stderr, "%s: %s\n", paths[i], entries[i].status)
+			}
+		}()
+	}
+	for i := range paths {
+		todo <- i
+	}
+	close(todo)
+	wg.Wait()
+
+	if err := writeIndex(filepath.Join(*out, "index.tsv"), entries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, e := range entries {
+		if e.err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// result of sweeping a single config
+type entry struct {
+	path   string
+	output string
+	conf   sim.Config
+	status string
+	err    error
+}
+
+// simulates the config at path, unless its results are there already.
+// results are written through a temporary file, so a killed sweep never leaves a partial results file behind.
+func sweep(path, out, format string) entry {
+	ext := format
+	if format == sim.FORMAT_TABLE {
+		ext = "csv"
+	}
+	e := entry{path: path, output: filepath.Join(out, filepath.Base(path)+"."+ext)}
+	fail := func(err error) entry {
+		e.err = err
+		e.status = "failed: " + err.Error()
+		return e
+	}
+	conf, err := sim.LoadConfig(path)
+	if err != nil {
+		return fail(err)
+	}
+	e.conf = conf
+	if _, err := os.Stat(e.output); err == nil {
+		e.status = "skipped"
+		return e
+	}
+
+	//the configs are already simulated in parallel, so the runs of each are not.
+	results, err := sim.SimulateRuns(conf, 1)
+	if err != nil {
+		return fail(err)
+	}
+	if err := sim.WriteResultsFile(e.output, format, conf, results); err != nil {
+		return fail(err)
+	}
+	e.status = "done"
+	return e
+}
+
+// expands the arguments into config files: every .json file of a directory, or the files matching a glob.
+// every file is listed once, in sorted order.
+func configPaths(args []string) ([]string, error) {
+	seen := make(map[string]bool)
+	names := make(map[string]string) //results file name -> config
+	paths := []string{}
+	for _, arg := range args {
+		var matches []string
+		var err error
+		if info, serr := os.Stat(arg); serr == nil && info.IsDir() {
+			matches, err = filepath.Glob(filepath.Join(arg, "*.json"))
+		} else {
+			matches, err = filepath.Glob(arg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no configs found", arg)
+		}
+		for _, p := range matches {
+			p = filepath.Clean(p)
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			if other, found := names[filepath.Base(p)]; found {
+				return nil, fmt.Errorf("%s and %s would write the same results file", other, p)
+			}
+			names[filepath.Base(p)] = p
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// writes a row per config: the config file, its results file, how the sweep went, then every config field.
+func writeIndex(path string, entries []entry) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	w.Comma = '\t'
+	names, _ := sim.ConfigFields(sim.Config{})
+	w.Write(append([]string{"config", "output", "status"}, names...))
+	for _, e := range entries {
+		_, values := sim.ConfigFields(e.conf)
+		w.Write(append([]string{e.path, e.output, e.status}, values...))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/lordalek/dat650-project/sim"
+)
+
+// exports the block tree of a single run of a config, with every fork and uncle, for visualisation.
+// only blocks with a timestamp in [from, to] are written; to defaults to the end of the run.
+//
+//	usage: tree [-run n] [-from t] [-to t] [-format dot|json] [-out file] config.json
+//	       tree -run 3 -to 20000 config.json | dot -Tsvg > run3.svg
+func main() {
+	run := flag.Int("run", 0, "run to export, from 0 to Runs-1")
+	from := flag.Int("from", 0, "start of the time window")
+	to := flag.Int("to", 0, "end of the time window, default the end of the run")
+	format := flag.String("format", sim.TREE_DOT, "format of the tree: dot or json")
+	out := flag.String("out", "", "file to write the tree to, default stdout")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format != sim.TREE_DOT && *format != sim.TREE_JSON {
+		fail(fmt.Errorf("unknown tree format %q", *format))
+	}
+	conf, err := sim.LoadConfig(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	if *run < 0 || *run >= conf.Runs {
+		fail(fmt.Errorf("run %d, config has runs 0 to %d", *run, conf.Runs-1))
+	}
+	if *to == 0 {
+		*to = conf.Time
+	}
+	if *from > *to {
+		fail(fmt.Errorf("time window from %d to %d is empty", *from, *to))
+	}
+
+	//runs are seeded by their number, so this is the same run as in the simulator's results.
+	result, err := sim.Simulate(conf, *run)
+	if err != nil {
+		fail(err)
+	}
+	w := os.Stdout
+	if *out != "" {
+		if w, err = os.Create(*out); err != nil {
+			fail(err)
+		}
+	}
+	err = sim.WriteTree(w, *format, result, *from, *to)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
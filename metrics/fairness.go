@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// Fairness describes how rewards are spread over miners compared to their mining power.
+// Gini, Jain and Theil are computed over the miners' reward share divided by their power share,
+// which is 1 for every miner if rewards are exactly proportional to mining power:
+// then Gini and Theil are 0 and Jain is 1.
+type Fairness struct {
+	Gini  float64 //0: fair, towards 1: rewards go to few miners beyond their power
+	Jain  float64 //1: fair, down to 1/n
+	Theil float64 //0: fair, up to ln(n)
+	Slope float64 //least squares slope of reward share on power share, 1: proportional, >1: favours strong miners
+}
+
+// Share is a miner's part of the total mining power and of the total rewards.
+type Share struct {
+	Power  float64
+	Reward float64
+}
+
+// fraction of the total mining power and of the total rewards of every miner, in the order given.
+// with no rewards at all every reward share is 0.
+func Shares(power, rewards []float64) []Share {
+	totPower, totRewards := sum(power), sum(rewards)
+	shares := make([]Share, len(power))
+	for i := range power {
+		if totPower > 0 {
+			shares[i].Power = power[i] / totPower
+		}
+		if totRewards > 0 {
+			shares[i].Reward = rewards[i] / totRewards
+		}
+	}
+	return shares
+}
+
+// fairness of the rewards of miners with the given mining power; miners without mining power are left out.
+func Compute(power, rewards []float64) Fairness {
+	shares := []Share{}
+	for _, s := range Shares(power, rewards) {
+		if s.Power > 0 {
+			shares = append(shares, s)
+		}
+	}
+	ratios := make([]float64, len(shares))
+	x, y := make([]float64, len(shares)), make([]float64, len(shares))
+	for i, s := range shares {
+		ratios[i] = s.Reward / s.Power
+		x[i], y[i] = s.Power, s.Reward
+	}
+	return Fairness{
+		Gini:  Gini(ratios),
+		Jain:  Jain(ratios),
+		Theil: Theil(ratios),
+		Slope: Slope(x, y),
+	}
+}
+
+// Gini coefficient of non-negative values: the mean absolute difference between two values over twice the mean.
+// 0 if all values are equal or there are none.
+func Gini(x []float64) float64 {
+	n, total := float64(len(x)), sum(x)
+	if total == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, x...)
+	sort.Float64s(sorted)
+	weighted := 0.0
+	for i, v := range sorted {
+		weighted += (2*float64(i+1) - n - 1) * v
+	}
+	return weighted / (n * total)
+}
+
+// Jain's fairness index (sum x)^2 / (n * sum x^2), from 1/n if a single value is positive to 1 if all are equal.
+// 1 if all values are 0 or there are none.
+func Jain(x []float64) float64 {
+	squares := 0.0
+	for _, v := range x {
+		squares += v * v
+	}
+	if squares == 0 {
+		return 1
+	}
+	total := sum(x)
+	return total * total / (float64(len(x)) * squares)
+}
+
+// Theil index of non-negative values: the mean of x/mean * ln(x/mean), taking 0 * ln 0 as 0.
+// 0 if all values are equal or all are 0.
+func Theil(x []float64) float64 {
+	total := sum(x)
+	if total == 0 {
+		return 0
+	}
+	mean := total / float64(len(x))
+	t := 0.0
+	for _, v := range x {
+		if v > 0 {
+			t += v / mean * math.Log(v/mean)
+		}
+	}
+	return t / float64(len(x))
+}
+
+// least squares slope of y on x; 0 if x does not vary, e.g. when all miners have the same mining power.
+func Slope(x, y []float64) float64 {
+	n := float64(len(x))
+	if n == 0 {
+		return 0
+	}
+	mx, my := sum(x)/n, sum(y)/n
+	cov, vx := 0.0, 0.0
+	for i := range x {
+		cov += (x[i] - mx) * (y[i] - my)
+		vx += (x[i] - mx) * (x[i] - mx)
+	}
+	//rounding leaves a tiny variance when all x are equal.
+	if vx <= 1e-12*n*mx*mx {
+		return 0
+	}
+	return cov / vx
+}
+
+func sum(x []float64) float64 {
+	total := 0.0
+	for _, v := range x {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFairnessIndices(t *testing.T) {
+	tests := []struct {
+		x                 []float64
+		gini, jain, theil float64
+	}{
+		{[]float64{}, 0, 1, 0},
+		{[]float64{0, 0, 0}, 0, 1, 0},
+		{[]float64{2, 2, 2, 2}, 0, 1, 0},
+		{[]float64{0, 0, 0, 4}, 0.75, 0.25, math.Log(4)}, //a single holder: (n-1)/n, 1/n and ln(n)
+		{[]float64{1, 3}, 0.25, 0.8, 0.5 * (1.5*math.Log(1.5) + 0.5*math.Log(0.5))},
+	}
+	for _, tt := range tests {
+		if got := Gini(tt.x); math.Abs(got-tt.gini) > 1e-9 {
+			t.Errorf("Gini(%v) = %v, want %v", tt.x, got, tt.gini)
+		}
+		if got := Jain(tt.x); math.Abs(got-tt.jain) > 1e-9 {
+			t.Errorf("Jain(%v) = %v, want %v", tt.x, got, tt.jain)
+		}
+		if got := Theil(tt.x); math.Abs(got-tt.theil) > 1e-9 {
+			t.Errorf("Theil(%v) = %v, want %v", tt.x, got, tt.theil)
+		}
+	}
+}
+
+func TestSlope(t *testing.T) {
+	tests := []struct {
+		x, y []float64
+		want float64
+	}{
+		{[]float64{}, []float64{}, 0},
+		{[]float64{0.1, 0.2, 0.7}, []float64{0.1, 0.2, 0.7}, 1},                     //proportional
+		{[]float64{0.1, 0.2, 0.3, 0.4}, []float64{0.025, 0.175, 0.325, 0.475}, 1.5}, //favours strong miners
+		{[]float64{1, 2, 3}, []float64{1, 3, 2}, 0.5},                               //not a line
+		{[]float64{0.25, 0.25, 0.25, 0.25}, []float64{0.1, 0.2, 0.3, 0.4}, 0},       //equal power
+	}
+	for _, tt := range tests {
+		if got := Slope(tt.x, tt.y); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Slope(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+// rewards proportional to mining power are fair, whatever the power; miners without power are left out.
+func TestCompute(t *testing.T) {
+	f := Compute([]float64{1, 2, 5, 0}, []float64{10, 20, 50, 0})
+	want := Fairness{Gini: 0, Jain: 1, Theil: 0, Slope: 1}
+	if math.Abs(f.Gini-want.Gini) > 1e-9 || math.Abs(f.Jain-want.Jain) > 1e-9 ||
+		math.Abs(f.Theil-want.Theil) > 1e-9 || math.Abs(f.Slope-want.Slope) > 1e-9 {
+		t.Errorf("Compute = %+v, want %+v", f, want)
+	}
+}
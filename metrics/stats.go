@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// Summary describes a sample of values, e.g. a miner's rewards over a number of runs.
+type Summary struct {
+	N      int
+	Mean   float64
+	SD     float64 //sample standard deviation, 0 for fewer than 2 values
+	SE     float64 //standard error of the mean
+	CILow  float64 //95% confidence interval of the mean, by Student's t distribution
+	CIHigh float64
+	Min    float64
+	Max    float64
+	Median float64
+}
+
+func Summarize(x []float64) Summary {
+	s := Summary{N: len(x)}
+	if s.N == 0 {
+		return s
+	}
+	sorted := append([]float64{}, x...)
+	sort.Float64s(sorted)
+	s.Min, s.Max = sorted[0], sorted[s.N-1]
+	if s.N%2 == 1 {
+		s.Median = sorted[s.N/2]
+	} else {
+		s.Median = (sorted[s.N/2-1] + sorted[s.N/2]) / 2
+	}
+	s.Mean = sum(x) / float64(s.N)
+	s.CILow, s.CIHigh = s.Mean, s.Mean
+	if s.N < 2 {
+		return s
+	}
+	squares := 0.0
+	for _, v := range x {
+		squares += (v - s.Mean) * (v - s.Mean)
+	}
+	s.SD = math.Sqrt(squares / float64(s.N-1))
+	s.SE = s.SD / math.Sqrt(float64(s.N))
+	margin := TQuantile(0.975, float64(s.N-1)) * s.SE
+	s.CILow, s.CIHigh = s.Mean-margin, s.Mean+margin
+	return s
+}
+
+// Welch is the outcome of Welch's t-test for a difference between the means of two samples.
+type Welch struct {
+	T  float64 //(mean a - mean b) / standard error of the difference
+	DF float64 //Welch-Satterthwaite degrees of freedom
+	P  float64 //two-sided p-value, 1 if the test is undefined
+}
+
+// Welch's t-test, which does not assume the samples have the same variance.
+// the test needs at least 2 values in each sample and some variance in either.
+func WelchTest(a, b []float64) Welch {
+	sa, sb := Summarize(a), Summarize(b)
+	if sa.N < 2 || sb.N < 2 {
+		return Welch{P: 1}
+	}
+	va, vb := sa.SE*sa.SE, sb.SE*sb.SE
+	if va+vb == 0 {
+		return Welch{P: 1}
+	}
+	w := Welch{T: (sa.Mean - sb.Mean) / math.Sqrt(va+vb)}
+	w.DF = (va + vb) * (va + vb) / (va*va/float64(sa.N-1) + vb*vb/float64(sb.N-1))
+	w.P = 2 * (1 - TCDF(math.Abs(w.T), w.DF))
+	return w
+}
+
+// cumulative distribution function of Student's t distribution with df degrees of freedom.
+func TCDF(t, df float64) float64 {
+	tail := incompleteBeta(df/(df+t*t), df/2, 0.5) / 2
+	if t < 0 {
+		return tail
+	}
+	return 1 - tail
+}
+
+// the t with TCDF(t, df) = p, found by bisection.
+func TQuantile(p, df float64) float64 {
+	lo, hi := -1e3, 1e3
+	for i := 0; i < 200 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if TCDF(mid, df) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// regularized incomplete beta function I_x(a, b), by its continued fraction (Numerical Recipes, betacf).
+func incompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	//the fraction converges fast for x below the mean of the distribution, otherwise use the symmetry.
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(1-x, b, a)/b
+	}
+	return front * betaFraction(x, a, b) / a
+}
+
+func betaFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1.0; m <= 300; m++ {
+		for _, even := range []bool{true, false} {
+			var num float64
+			if even {
+				num = m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+			} else {
+				num = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+			}
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+			if !even && math.Abs(d*c-1) < 1e-15 {
+				return h
+			}
+		}
+	}
+	return h
+}
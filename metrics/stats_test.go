@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTQuantile(t *testing.T) {
+	tests := []struct {
+		p, df float64
+		want  float64
+	}{
+		{0.975, 3, 3.1824},
+		{0.975, 19, 2.0930},
+		{0.975, 1, 12.7062},
+		{0.95, 10, 1.8125},
+		{0.5, 7, 0},
+	}
+	for _, tt := range tests {
+		if got := TQuantile(tt.p, tt.df); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("TQuantile(%v, %v) = %.5f, want %.4f", tt.p, tt.df, got, tt.want)
+		}
+		if tt.want > 0 {
+			if got := TCDF(tt.want, tt.df); math.Abs(got-tt.p) > 1e-5 {
+				t.Errorf("TCDF(%v, %v) = %.6f, want %v", tt.want, tt.df, got, tt.p)
+			}
+		}
+	}
+}
+
+func TestIncompleteBeta(t *testing.T) {
+	tests := []struct {
+		x, a, b float64
+		want    float64
+	}{
+		{0, 2, 5, 0},
+		{1, 2, 5, 1},
+		{0.3, 1, 1, 0.3},          //uniform distribution
+		{0.3, 2, 5, 0.579825},     //1 - 0.7^6 - 6*0.3*0.7^5
+		{0.7, 5, 2, 1 - 0.579825}, //I_x(a,b) = 1 - I_{1-x}(b,a)
+		{0.5, 3, 3, 0.5},          //symmetric
+		{0.2, 0.5, 0.5, 2 / math.Pi * math.Asin(math.Sqrt(0.2))}, //arcsine distribution
+	}
+	for _, tt := range tests {
+		if got := incompleteBeta(tt.x, tt.a, tt.b); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("incompleteBeta(%v, %v, %v) = %.7f, want %.7f", tt.x, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWelchTest(t *testing.T) {
+	a := []float64{19.8, 20.4, 19.6, 17.8, 18.5, 18.9, 18.3, 18.9, 19.5, 22.0}
+	b := []float64{28.2, 26.6, 20.1, 23.3, 25.2, 22.1, 17.7, 27.6, 20.6, 13.7,
+		23.2, 17.5, 20.6, 18.0, 23.9, 21.6, 24.3, 20.4, 23.9, 13.3}
+	w := WelchTest(a, b)
+	if math.Abs(w.T-(-2.2255)) > 1e-4 || math.Abs(w.DF-24.52) > 1e-2 || math.Abs(w.P-0.0355) > 1e-4 {
+		t.Errorf("WelchTest = %+v, want t -2.2255, df 24.52, p 0.0355", w)
+	}
+	if r := WelchTest(b, a); r.T != -w.T || r.DF != w.DF || r.P != w.P {
+		t.Errorf("swapped samples: %+v, want t %v, df %v, p %v", r, -w.T, w.DF, w.P)
+	}
+
+	//undefined tests have p 1.
+	for _, samples := range [][2][]float64{
+		{{1}, {1, 2}},
+		{{3, 3, 3}, {3, 3}},
+	} {
+		if w := WelchTest(samples[0], samples[1]); w.P != 1 {
+			t.Errorf("WelchTest(%v, %v) = %+v, want p 1", samples[0], samples[1], w)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Block struct {
+	id        string
+	minerID   string
+	parent    *Block
+	uncles    map[string]*Block
+	sorted    []*Block //uncles in order of id
+	timestamp int
+	fees      int
+	depth     int
+	selfish   bool //mined by a selfish miner
+	//miners that have seen the block, kept with the block rather than the miner,
+	//so checking whether a neighbor has seen it is cheap while it spreads.
+	seenBy map[*HonestMiner]bool
+	//difficulty of mining the block, and the sum of difficulties of the chain ending in the block
+	difficulty      float64
+	totalDifficulty float64
+}
+
+// initializes new block with a given parent, list of uncles and a timestamp
+// timestamp used to determine transaction fees included in block reward
+func NewBlock(minerID string, parent *Block, uncles map[string]*Block, timestamp int) *Block {
+	newDepth := -1
+	newFees := 0
+	totalDifficulty := 1.0
+	if parent != nil {
+		newDepth = parent.depth + 1
+		newFees = (timestamp - parent.timestamp) * FEES_PER_SECOND
+		totalDifficulty += parent.totalDifficulty
+	}
+
+	buncles := make(map[string]*Block)
+	sorted := make([]*Block, 0, len(uncles))
+	for k, v := range uncles {
+		buncles[k] = v
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetID() < sorted[j].GetID()
+	})
+	return &Block{
+		id:        fmt.Sprintf("%s_d%d_t%d", minerID, newDepth, timestamp),
+		minerID:   minerID,
+		parent:    parent,
+		uncles:    buncles,
+		sorted:    sorted,
+		timestamp: timestamp,
+		fees:      newFees,
+		depth:     newDepth,
+
+		difficulty:      1,
+		totalDifficulty: totalDifficulty,
+	}
+}
+
+func (b *Block) markSeen(m *HonestMiner) {
+	if b.seenBy == nil {
+		b.seenBy = make(map[*HonestMiner]bool)
+	}
+	b.seenBy[m] = true
+}
+
+// difficulty is set by the miner, once the block is mined.
+func (b *Block) setDifficulty(d float64) {
+	b.difficulty = d
+	b.totalDifficulty = d
+	if b.parent != nil {
+		b.totalDifficulty += b.parent.totalDifficulty
+	}
+}
+
+// the id is made up of the miner's id, the depth and the timestamp, fixed when the block is created
+func (b *Block) GetID() string {
+	return b.id
+}
+
+func (b *Block) GetMinerID() string {
+	return b.minerID
+}
+
+func (b *Block) GetParent() *Block {
+	return b.parent
+}
+
+func (b *Block) GetTimestamp() int {
+	return b.timestamp
+}
+
+func (b *Block) GetDepth() int {
+	return b.depth
+}
+
+func (b *Block) IsSelfish() bool {
+	return b.selfish
+}
+
+func (b *Block) GetDifficulty() float64 {
+	return b.difficulty
+}
+
+func (b *Block) GetTotalDifficulty() float64 {
+	return b.totalDifficulty
+}
+
+// true if b is a strict ancestor of d
+func (b *Block) IsAncestorOf(d *Block) bool {
+	if d.depth <= b.depth {
+		return false
+	}
+	for d.depth > b.depth {
+		d = d.parent
+	}
+	return d.Equals(b)
+}
+
+// deepest block on both the chain ending in a and the chain ending in b
+func CommonAncestor(a, b *Block) *Block {
+	for a.depth > b.depth {
+		a = a.parent
+	}
+	for b.depth > a.depth {
+		b = b.parent
+	}
+	for !a.Equals(b) {
+		a = a.parent
+		b = b.parent
+	}
+	return a
+}
+
+// uncles of the block in order of id, so iterating over them is reproducible.
+// the uncles are sorted once, when the block is created; the slice must not be changed.
+func (b *Block) sortedUncles() []*Block {
+	return b.sorted
+}
+
+func (b *Block) String() string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("============ Block %s ============", b.GetID()))
+	lines = append(lines, fmt.Sprintf("Parent: %s", b.parent.GetID()))
+	lines = append(lines, fmt.Sprintf("Block reward: %d", b.fees))
+	lines = append(lines, fmt.Sprintf("depth: %d", b.depth))
+	lines = append(lines, fmt.Sprintf("Uncles: %d", len(b.uncles)))
+	for _, u := range b.sortedUncles() {
+		lines = append(lines, fmt.Sprintf("\t%s", u.GetID()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *Block) Equals(block *Block) bool {
+	return b.GetID() == block.GetID()
+}
@@ -0,0 +1,190 @@
+package sim
+
+// Coalition is the state shared by colluding selfish miners.
+// members mine on one private chain, held by chain, and publish through each of their own neighbors.
+// a selfish miner acting alone is a coalition of one.
+// publicTip tracks the tip the honest network prefers by the fork choice rule, applied to published blocks only,
+// and withheld holds the private blocks not yet published, in order of depth.
+// what to publish and which tip to mine on is delegated to the strategy.
+// with an uncle policy that recoups own blocks, blocks orphaned by switching chain are kept as pending uncles.
+type Coalition struct {
+	id          string
+	chain       *HonestMiner
+	strategy    Strategy
+	unclePolicy *UnclePolicy
+	publicTip   *Block
+	publicView  ForkChoice
+	withheld    []*Block
+	members     []*SelfishMiner
+	ticked      map[string]bool //members that passed the current communication step
+}
+
+func NewCoalition(id string, strategy Strategy, unclePolicy *UnclePolicy) *Coalition {
+	chain := NewMiner(id, nil, 0, 0).(*HonestMiner)
+	chain.SetUnclePolicy(unclePolicy)
+	return &Coalition{
+		id:          id,
+		chain:       chain,
+		strategy:    strategy,
+		unclePolicy: unclePolicy,
+		publicTip:   chain.GetLastBlock(),
+		publicView:  &longestChain{},
+		withheld:    []*Block{},
+		members:     []*SelfishMiner{},
+		ticked:      make(map[string]bool),
+	}
+}
+
+func (c *Coalition) GetID() string {
+	return c.id
+}
+
+func (c *Coalition) GetStrategy() Strategy {
+	return c.strategy
+}
+
+func (c *Coalition) GetMembers() []*SelfishMiner {
+	return c.members
+}
+
+// the fork choice rule is only used to follow the public chain, the private chain is up to the strategy.
+func (c *Coalition) SetForkChoice(f ForkChoice) {
+	c.publicView = f
+	c.publicView.AddBlock(c.publicTip)
+}
+
+func (c *Coalition) IsMember(minerID string) bool {
+	for _, m := range c.members {
+		if m.GetID() == minerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Coalition) AddMember(s *SelfishMiner) {
+	c.members = append(c.members, s)
+}
+
+// builds a new block on the private chain, credited to the member that found it.
+func (c *Coalition) blockFound(minerID string, timestamp, maxDepth, maxUncles int) *Block {
+	return c.chain.mineBlock(minerID, c.IsMember, timestamp, maxDepth, maxUncles)
+}
+
+// a member extended the private chain with b.
+func (c *Coalition) mined(b *Block) {
+	b.selfish = true
+	c.chain.AppendBlock(b)
+	c.withheld = append(c.withheld, b)
+	c.consult(EVENT_MINED, b)
+}
+
+// a member received a block not seen by the coalition before.
+// blocks that make the honest network switch to another tip are passed on to the strategy.
+func (c *Coalition) received(b *Block) {
+	c.chain.AppendUncle(b)
+	c.publicView.AddBlock(b)
+	for _, u := range b.sortedUncles() {
+		c.publicView.AddBlock(u)
+	}
+	candidate := c.publicView.Candidate(c.publicTip, b)
+	if c.publicView.Compare(candidate, c.publicTip) <= 0 {
+		return
+	}
+	c.publicTip = candidate
+	c.consult(EVENT_PUBLIC, candidate)
+}
+
+// the strategy is consulted once per communication step, when the first member passes it.
+// members that are offline skip the step, so a new step starts once a member passes it again.
+func (c *Coalition) tick(minerID string) {
+	if len(c.ticked) == 0 || c.ticked[minerID] {
+		c.ticked = make(map[string]bool)
+		c.consult(EVENT_TICK, nil)
+	}
+	c.ticked[minerID] = true
+}
+
+func (c *Coalition) view(event int, b *Block) *StrategyView {
+	return &StrategyView{
+		Event:      event,
+		Block:      b,
+		PrivateTip: c.chain.GetLastBlock(),
+		PublicTip:  c.publicTip,
+		Withheld:   c.withheld,
+	}
+}
+
+// asks the strategy what to publish, then which tip to mine on.
+// switching to another tip abandons withheld blocks not on the new chain.
+func (c *Coalition) consult(event int, b *Block) {
+	c.publish(c.strategy.Publish(c.view(event, b)))
+	tip := c.strategy.MiningTip(c.view(event, b))
+	oldTip := c.chain.GetLastBlock()
+	if tip.Equals(oldTip) {
+		return
+	}
+	c.chain.SwitchChain(tip)
+	if c.unclePolicy.RecoupOwn {
+		for _, i := range orphanedBlocks(oldTip, tip) {
+			if c.IsMember(i.minerID) {
+				c.chain.AppendUncle(i)
+			}
+		}
+	}
+	onChain := make(map[string]bool)
+	for i := tip; i != nil && len(c.withheld) > 0 && i.depth >= c.withheld[0].depth; i = i.parent {
+		onChain[i.GetID()] = true
+	}
+	withheld := []*Block{}
+	for _, i := range c.withheld {
+		if onChain[i.GetID()] {
+			withheld = append(withheld, i)
+		}
+	}
+	c.withheld = withheld
+}
+
+// published blocks are shared with the neighbors of every member during the next communication step.
+func (c *Coalition) publish(blocks []*Block) {
+	if len(blocks) == 0 {
+		return
+	}
+	published := make(map[string]bool)
+	for _, b := range blocks {
+		published[b.GetID()] = true
+		for _, m := range c.members {
+			m.EnqueueBlock(b)
+		}
+		c.publicView.AddBlock(b)
+		if c.publicView.Compare(b, c.publicTip) > 0 {
+			c.publicTip = b
+		}
+	}
+	withheld := []*Block{}
+	for _, b := range c.withheld {
+		if !published[b.GetID()] {
+			withheld = append(withheld, b)
+		}
+	}
+	c.withheld = withheld
+}
+
+// blocks on the chain ending in oldTip that are not on the chain ending in newTip
+func orphanedBlocks(oldTip, newTip *Block) []*Block {
+	orphans := []*Block{}
+	o, n := oldTip, newTip
+	for o.depth > n.depth {
+		orphans = append(orphans, o)
+		o = o.parent
+	}
+	for n.depth > o.depth {
+		n = n.parent
+	}
+	for !o.Equals(n) {
+		orphans = append(orphans, o)
+		o = o.parent
+		n = n.parent
+	}
+	return orphans
+}
@@ -0,0 +1,235 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Config holds the parameters of a simulation, read from a JSON config file.
+type Config struct {
+	Runs               int     //default 20
+	Time               int     //default 10^7
+	Miners             int     //default 100
+	MaxUncles          int     //limit of uncles included per block: min. 0, default max 2
+	PowerScaling       float64 //number equal to or greater than 1.0, miner n will have pS^n mining power, default 1
+	MaxDepth           int     //how old can an uncle be before all its value diminishes? default 7
+	UncleDivisor       float64 //static divisor on uncle reward, default 1
+	NephewReward       float64 //portion of a block reward given to nephew per uncle included
+	SelfishMiners      int     //number of selfish miners, all set up by the Selfish* fields below; ignored if Adversaries is given
+	SelfishDelay       int     //how many rounds does a selfish miner wait before publishing a block?
+	SelfishPower       float64 //percentile of regular miners the selfish miner has more mining power than, (0,1)
+	SelfishStrategy    string  //"delay" (default), "sm1", "lead-stubborn", "equal-fork-stubborn" or "trail-stubborn"
+	TrailDepth         int     //how many blocks a trail-stubborn miner may fall behind before adopting the public chain
+	SelfishUnclePolicy string  //"freshest" (default), "recoup": reference own orphaned blocks first, "recoup-deny": reference only own blocks
+	Gamma              float64 //fraction of honest miners that switch to the selfish block in a tie, [0,1]; first-seen tie break only
+	ForkChoice         string  //"longest" (default), "ghost" or "heaviest"
+	TieBreak           string  //"first-seen" (default), "random", "timestamp" or "own"
+	Retarget           string  //difficulty adjustment: "none" (default), "homestead", "ethereum" or "bitcoin"
+	RetargetInterval   int     //blocks between bitcoin difficulty adjustments, default 2016
+	Latency            string  //block propagation delay: "tick" (default), "constant", "exponential" or "matrix"
+	LatencyMean        int     //delay in time units for "constant", mean delay for "exponential", delay of links missing from the matrix
+	LatencyFile        string  //csv file of delays between miners for "matrix"
+	Bandwidth          float64 //kB per time unit on every link not in BandwidthFile, 0: unlimited
+	BandwidthFile      string  //csv file of bandwidths between miners, in the format of LatencyFile
+	BlockSize          float64 //kB per block, taking BlockSize/Bandwidth time units to send over a link
+	Topology           string  //"random" (default), "random-regular", "erdos-renyi", "watts-strogatz", "barabasi-albert", "full" or "file"
+	Degree             int     //links per miner the topology aims for, also the links of miners joining later, default 5
+	EdgeProbability    float64 //"erdos-renyi": probability of a link between two miners, default Degree/(Miners-1)
+	Rewire             float64 //"watts-strogatz": probability of rewiring a link of the ring, [0,1]
+	TopologyFile       string  //"file": edge list, one pair of miner ids per line
+	Adversaries        []Adversary
+	Schedule           []PowerEvent //miners joining, leaving or changing mining power during a run
+	Seed               int64        //run n is seeded with Seed+n, default 1230
+}
+
+const (
+	DEFAULT_RUNS   = 20
+	DEFAULT_TIME   = 10000000
+	DEFAULT_MINERS = 100
+	DEFAULT_SEED   = 1230
+
+	DEFAULT_POWER_SCALING = 1
+	DEFAULT_MAX_DEPTH     = 7
+	DEFAULT_UNCLE_DIVISOR = 1
+)
+
+// reads the config in the JSON file at path, fills in the defaults of fields left out or zero, and validates it.
+func LoadConfig(path string) (Config, error) {
+	conf, err := ReadConfig(path)
+	if err != nil {
+		return conf, err
+	}
+	conf.SetDefaults()
+	if err := conf.Validate(); err != nil {
+		return conf, fmt.Errorf("%s: %v", path, err)
+	}
+	return conf, nil
+}
+
+// reads the config in the JSON file at path as it is.
+// fields the config does not have are rejected, so a misspelled field is an error rather than a zero value.
+func ReadConfig(path string) (Config, error) {
+	conf := Config{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return conf, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&conf); err != nil {
+		return conf, fmt.Errorf("%s: %v", path, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return conf, fmt.Errorf("%s: unexpected data after the config", path)
+	}
+	return conf, nil
+}
+
+// sets Runs, Time, Miners, PowerScaling, MaxDepth and UncleDivisor to their defaults if they are zero.
+// none of the last three has a use at zero: only the first miner would mine, no uncle could be included or rewarded.
+func (c *Config) SetDefaults() {
+	if c.Runs == 0 {
+		c.Runs = DEFAULT_RUNS
+	}
+	if c.Time == 0 {
+		c.Time = DEFAULT_TIME
+	}
+	if c.Miners == 0 {
+		c.Miners = DEFAULT_MINERS
+	}
+	if c.PowerScaling == 0 {
+		c.PowerScaling = DEFAULT_POWER_SCALING
+	}
+	if c.MaxDepth == 0 {
+		c.MaxDepth = DEFAULT_MAX_DEPTH
+	}
+	if c.UncleDivisor == 0 {
+		c.UncleDivisor = DEFAULT_UNCLE_DIVISOR
+	}
+}
+
+// checks the fields of a config with its defaults set that have a valid range;
+// names of strategies, models etc. are checked when the simulation is set up.
+func (c Config) Validate() error {
+	switch {
+	case c.Runs < 1:
+		return fmt.Errorf("Runs is %d, must be at least 1", c.Runs)
+	case c.Time < 1:
+		return fmt.Errorf("Time is %d, must be at least 1", c.Time)
+	case c.Miners < 1:
+		return fmt.Errorf("Miners is %d, must be at least 1", c.Miners)
+	case c.MaxUncles < 0:
+		return fmt.Errorf("MaxUncles is %d, must not be negative", c.MaxUncles)
+	case c.PowerScaling < 1:
+		return fmt.Errorf("PowerScaling is %v, must be at least 1", c.PowerScaling)
+	case c.MaxDepth < 0:
+		return fmt.Errorf("MaxDepth is %d, must not be negative", c.MaxDepth)
+	case c.UncleDivisor < 0:
+		return fmt.Errorf("UncleDivisor is %v, must not be negative", c.UncleDivisor)
+	case c.NephewReward < 0:
+		return fmt.Errorf("NephewReward is %v, must not be negative", c.NephewReward)
+	case c.SelfishMiners < 0:
+		return fmt.Errorf("SelfishMiners is %d, must not be negative", c.SelfishMiners)
+	case c.Gamma < 0 || c.Gamma > 1:
+		return fmt.Errorf("Gamma is %v, must be in [0,1]", c.Gamma)
+	case c.Rewire < 0 || c.Rewire > 1:
+		return fmt.Errorf("Rewire is %v, must be in [0,1]", c.Rewire)
+	case c.EdgeProbability < 0 || c.EdgeProbability > 1:
+		return fmt.Errorf("EdgeProbability is %v, must be in [0,1]", c.EdgeProbability)
+	}
+	adversaries, err := c.GetAdversaries()
+	if err != nil {
+		return err
+	}
+	//errors about the Selfish* fields name those, not the adversaries set up from them.
+	power, delay := "SelfishPower", "SelfishDelay"
+	first := make(map[string]Adversary) //coalition id -> its first member
+	for _, a := range adversaries {
+		if len(c.Adversaries) > 0 {
+			power, delay = fmt.Sprintf("adversary %s: Power", a.ID), fmt.Sprintf("adversary %s: Delay", a.ID)
+		}
+		if a.Power <= 0 || a.Power >= 1 {
+			return fmt.Errorf("%s is %v, must be in (0,1)", power, a.Power)
+		}
+		if (a.Strategy == "" || a.Strategy == STRATEGY_DELAY) && a.Delay < 1 {
+			return fmt.Errorf("%s is %d, must be at least 1 for the %s strategy", delay, a.Delay, STRATEGY_DELAY)
+		}
+		//members of a coalition mine on one private chain, so they cannot follow different strategies.
+		f, found := first[a.Coalition]
+		if !found {
+			first[a.Coalition] = a
+			continue
+		}
+		if f.strategyName() != a.strategyName() || f.Delay != a.Delay || f.TrailDepth != a.TrailDepth ||
+			f.unclePolicyName() != a.unclePolicyName() {
+			return fmt.Errorf("adversary %s: Strategy, Delay, TrailDepth and UnclePolicy must be those of %s, the first member of coalition %s",
+				a.ID, f.ID, a.Coalition)
+		}
+	}
+	return nil
+}
+
+// Adversary describes a single selfish miner.
+// adversaries sharing a coalition id mine on one private chain, so they need the same strategy
+// and uncle policy; their gains are also reported pooled under the coalition id.
+type Adversary struct {
+	ID          string  //default s<n>, n being the adversary's index
+	Power       float64 //percentile of regular miners the adversary has more mining power than, (0,1)
+	Delay       int
+	Strategy    string
+	TrailDepth  int
+	UnclePolicy string
+	Coalition   string   //default: the adversary's own id, i.e. no collusion
+	ExtraLinks  int      //links to random miners on top of those of the topology
+	TopLinks    int      //links to the honest miners with the most mining power
+	Eclipse     []string //ids of honest miners whose only neighbors become this adversary and others eclipsing them
+}
+
+// returns the adversaries of the simulation with default ids and coalitions filled in.
+// without explicit Adversaries, SelfishMiners adversaries are set up from the Selfish* fields.
+func (c Config) GetAdversaries() ([]Adversary, error) {
+	adversaries := append([]Adversary{}, c.Adversaries...)
+	if len(adversaries) == 0 {
+		for i := 0; i < c.SelfishMiners; i++ {
+			adversaries = append(adversaries, Adversary{
+				Power:       c.SelfishPower,
+				Delay:       c.SelfishDelay,
+				Strategy:    c.SelfishStrategy,
+				TrailDepth:  c.TrailDepth,
+				UnclePolicy: c.SelfishUnclePolicy,
+			})
+		}
+	}
+	ids := make(map[string]bool)
+	for i := range adversaries {
+		if adversaries[i].ID == "" {
+			adversaries[i].ID = fmt.Sprintf("s%d", i)
+		}
+		if adversaries[i].Coalition == "" {
+			adversaries[i].Coalition = adversaries[i].ID
+		}
+		if ids[adversaries[i].ID] {
+			return nil, fmt.Errorf("duplicate adversary id %q", adversaries[i].ID)
+		}
+		ids[adversaries[i].ID] = true
+	}
+	return adversaries, nil
+}
+
+// names of the adversary's strategy and uncle policy, with the defaults filled in.
+func (a Adversary) strategyName() string {
+	if a.Strategy == "" {
+		return STRATEGY_DELAY
+	}
+	return a.Strategy
+}
+
+func (a Adversary) unclePolicyName() string {
+	if a.UnclePolicy == "" {
+		return UNCLES_FRESHEST
+	}
+	return a.UnclePolicy
+}
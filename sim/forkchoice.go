@@ -0,0 +1,160 @@
+package sim
+
+import (
+	"fmt"
+)
+
+const (
+	FORK_LONGEST  = "longest"
+	FORK_GHOST    = "ghost"
+	FORK_HEAVIEST = "heaviest"
+)
+
+// ForkChoice decides which of the chains known to a miner is the main chain.
+// a fork choice keeps track of the blocks known to a single miner, so every miner needs its own.
+type ForkChoice interface {
+	GetName() string
+	//records a block known to the miner, along with any unknown ancestors
+	AddBlock(*Block)
+	//tip of the chain the miner should consider switching to, now that b is known and current is the last block
+	Candidate(current, b *Block) *Block
+	//>0 if the chain ending in a is preferred over the chain ending in b, <0 if b is preferred, 0 if tied
+	Compare(a, b *Block) int
+}
+
+// creates a fork choice rule by name:
+//
+//	longest (default): the chain with the most blocks
+//	ghost: at every fork, the branch whose subtree holds the most blocks, counting stale blocks and uncles
+//	heaviest: the chain with the highest cumulative difficulty
+func NewForkChoice(name string) (ForkChoice, error) {
+	switch name {
+	case "", FORK_LONGEST:
+		return &longestChain{}, nil
+	case FORK_GHOST:
+		return &ghost{known: make(map[string]bool), children: make(map[string][]*Block)}, nil
+	case FORK_HEAVIEST:
+		return &heaviestChain{}, nil
+	}
+	return nil, fmt.Errorf("unknown fork choice rule %q", name)
+}
+
+type longestChain struct{}
+
+func (l *longestChain) GetName() string {
+	return FORK_LONGEST
+}
+
+func (l *longestChain) AddBlock(b *Block) {}
+
+func (l *longestChain) Candidate(current, b *Block) *Block {
+	return b
+}
+
+func (l *longestChain) Compare(a, b *Block) int {
+	return a.depth - b.depth
+}
+
+type heaviestChain struct{}
+
+func (h *heaviestChain) GetName() string {
+	return FORK_HEAVIEST
+}
+
+func (h *heaviestChain) AddBlock(b *Block) {}
+
+func (h *heaviestChain) Candidate(current, b *Block) *Block {
+	return b
+}
+
+func (h *heaviestChain) Compare(a, b *Block) int {
+	switch {
+	case a.totalDifficulty > b.totalDifficulty:
+		return 1
+	case a.totalDifficulty < b.totalDifficulty:
+		return -1
+	}
+	return 0
+}
+
+// greedy heaviest observed subtree.
+// subtree weights are counted on demand; forks are compared where they split,
+// so only the blocks after the fork point are visited.
+type ghost struct {
+	known    map[string]bool
+	children map[string][]*Block
+}
+
+func (g *ghost) GetName() string {
+	return FORK_GHOST
+}
+
+func (g *ghost) AddBlock(b *Block) {
+	for b != nil && !g.known[b.GetID()] {
+		g.known[b.GetID()] = true
+		if b.parent != nil {
+			g.children[b.parent.GetID()] = append(g.children[b.parent.GetID()], b)
+		}
+		b = b.parent
+	}
+}
+
+// descends from the fork point of current and b along the heaviest children, starting on b's side.
+func (g *ghost) Candidate(current, b *Block) *Block {
+	fork := CommonAncestor(current, b)
+	if fork.Equals(b) {
+		return current
+	}
+	tip := childTowards(fork, b)
+	for {
+		var heaviest *Block
+		weight := 0
+		for _, c := range g.children[tip.GetID()] {
+			//ties go to the first known child.
+			if w := g.weight(c, -1); w > weight {
+				heaviest, weight = c, w
+			}
+		}
+		if heaviest == nil {
+			return tip
+		}
+		tip = heaviest
+	}
+}
+
+func (g *ghost) Compare(a, b *Block) int {
+	fork := CommonAncestor(a, b)
+	switch {
+	case fork.Equals(a) && fork.Equals(b):
+		return 0
+	case fork.Equals(b):
+		return 1
+	case fork.Equals(a):
+		return -1
+	}
+	wa := g.weight(childTowards(fork, a), -1)
+	//counting the other side can stop as soon as it outweighs a.
+	wb := g.weight(childTowards(fork, b), wa+1)
+	return wa - wb
+}
+
+// number of known blocks in the subtree rooted at b; counting stops once limit is reached, unless limit < 0.
+func (g *ghost) weight(b *Block, limit int) int {
+	w := 0
+	stack := []*Block{b}
+	for len(stack) > 0 && (limit < 0 || w < limit) {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		w++
+		stack = append(stack, g.children[n.GetID()]...)
+	}
+	return w
+}
+
+// ancestor of b, or b itself, that is a child of ancestor
+func childTowards(ancestor, b *Block) *Block {
+	for b.depth > ancestor.depth+1 {
+		b = b.parent
+	}
+	return b
+}
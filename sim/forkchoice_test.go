@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestForkChoice(t *testing.T) {
+	genesis := NewBlock("genesis", nil, nil, 0)
+	blocks := map[string]*Block{}
+	order := []*Block{} //ghost breaks ties by the order blocks are known in
+	add := func(name string, parent *Block) *Block {
+		blocks[name] = NewBlock("m", parent, nil, len(order)+1)
+		order = append(order, blocks[name])
+		return blocks[name]
+	}
+	//a is the longest chain, b the largest subtree, c the single block of highest difficulty.
+	a1 := add("a1", genesis)
+	a2 := add("a2", a1)
+	a3 := add("a3", a2)
+	add("a4", a3)
+	b1 := add("b1", genesis)
+	for _, name := range []string{"b2a", "b2b", "b2c", "b2d"} {
+		add(name, b1)
+	}
+	add("c1", genesis).setDifficulty(10)
+	tips := []string{"a4", "b2a", "b2b", "b2c", "b2d", "c1"}
+
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{FORK_LONGEST, "a4"},
+		{FORK_GHOST, "b2a"}, //ties among the children of b1 go to the first
+		{FORK_HEAVIEST, "c1"},
+	}
+	for _, tt := range tests {
+		fc, err := NewForkChoice(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range order {
+			fc.AddBlock(b)
+		}
+		best := tips[0]
+		for _, tip := range tips[1:] {
+			if fc.Compare(blocks[tip], blocks[best]) > 0 {
+				best = tip
+			}
+		}
+		if best != tt.want {
+			t.Errorf("%s prefers %s, want %s", tt.rule, best, tt.want)
+		}
+		for _, tip := range tips {
+			if c := fc.Compare(blocks[tip], blocks[tip]); c != 0 {
+				t.Errorf("%s compares %s to itself as %d, want 0", tt.rule, tip, c)
+			}
+		}
+	}
+
+	candidates := []struct {
+		rule       string
+		current, b string
+		want       string
+	}{
+		{FORK_LONGEST, "a4", "b2c", "b2c"},
+		{FORK_HEAVIEST, "a4", "c1", "c1"},
+		{FORK_GHOST, "a4", "b1", "b2a"}, //descends from b1 to its heaviest child
+		{FORK_GHOST, "b2c", "a2", "a4"},
+		{FORK_GHOST, "a4", "a2", "a4"}, //an ancestor of current offers nothing new
+	}
+	for _, tt := range candidates {
+		fc, err := NewForkChoice(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range order {
+			fc.AddBlock(b)
+		}
+		if got := fc.Candidate(blocks[tt.current], blocks[tt.b]); got != blocks[tt.want] {
+			t.Errorf("%s candidate from %s for %s is %v, want %s", tt.rule, tt.current, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+type Miner interface {
+	GetID() string
+	GetMiningPower() int
+	SetMiningPower(int)
+	GenerateNeighbors([]Miner, int, bool)
+	SetNeighbors([]Miner)
+	GetNeighbors() []Miner
+	SetUnclePolicy(*UnclePolicy)
+	SetForkChoice(ForkChoice)
+	SetTieBreak(TieBreak)
+	SetRetarget(Retarget)
+	SetNetwork(*Network)
+	SetRand(*rand.Rand)
+	AddNeighbor(Miner, bool)
+
+	TickMine(int, int, int, int)
+	Mine(int, int, int, int) *Block
+	BlockFound(int, int, int) *Block
+	Mined(*Block)
+	GetPendingUncles() map[string]*Block
+
+	TickCommunicate()
+	SendBlock(*Block)
+	EnqueueBlock(*Block)
+	PublishBlock(*Block)
+
+	TickRead()
+	GetReadQueue() []*Block
+	ClearReadQueue()
+	ReceiveBlock(*Block)
+	RecordBlock(*Block) bool
+	SwitchChain(*Block)
+	HasSeen(*Block) bool
+	AppendBlock(*Block)
+	AddBlocks([]*Block)
+	RemoveBlock(*Block)
+	RemoveBlocks([]*Block)
+	AppendUncle(*Block)
+	IncludeUncle(*Block)
+	IncludeUncles([]*Block)
+
+	GetBlockchain() []*Block
+	GetLastBlock() *Block
+	CalculateGains(int, float64, float64) map[string][]float64
+}
+
+type HonestMiner struct {
+	blockchain    []*Block
+	pendingUncles map[string]*Block
+	maxUncles     int
+	neighbors     []Miner
+	miningPower   int
+	id            string
+	readQueue     []*Block
+	publishQueue  []*Block
+	unclePolicy   *UnclePolicy
+	forkChoice    ForkChoice
+	tieBreak      TieBreak
+	retarget      Retarget
+	network       *Network
+	rng           *rand.Rand
+}
+
+// initializes new miner with the first neighbor's blockchain and uncles, and the list of neighbors as neighbors
+// neighbor list optional, can be added later
+func NewMiner(name string, neighbors []Miner, mining_power, maxUncles int) Miner {
+	genesisBlock := NewBlock("genesis", nil, nil, 0)
+	bc := []*Block{}
+	if len(neighbors) != 0 {
+		bc = neighbors[0].GetBlockchain()
+	} else {
+		bc = []*Block{genesisBlock}
+	}
+	rng := rand.New(rand.NewSource(1))
+	return &HonestMiner{
+		blockchain:    bc,
+		maxUncles:     maxUncles,
+		pendingUncles: make(map[string]*Block),
+		neighbors:     neighbors,
+		miningPower:   mining_power,
+		id:            name,
+		readQueue:     []*Block{},
+		publishQueue:  []*Block{},
+		unclePolicy:   &UnclePolicy{Name: UNCLES_FRESHEST},
+		forkChoice:    &longestChain{},
+		tieBreak:      &firstSeen{rng: rng},
+		retarget:      &constantDifficulty{initial: BLOCK_TIME},
+		rng:           rng,
+	}
+}
+
+func (m *HonestMiner) String() string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("============ Miner %s ============", m.id))
+	//absolute and relative mining power
+	//blocks produced; included in chain; included as uncles
+	//rewards gained; reward per mining power
+	return strings.Join(lines, "\n")
+}
+
+func (m *HonestMiner) GetID() string {
+	return m.id
+}
+
+func (m *HonestMiner) GetMiningPower() int {
+	return m.miningPower
+}
+
+func (m *HonestMiner) SetMiningPower(p int) {
+	m.miningPower = p
+}
+
+// selects n neighbors from the set of miners, other than m and its current neighbors
+// mutual specifies if neighbor also makes m its neighbor
+func (m *HonestMiner) GenerateNeighbors(miners []Miner, n int, mutual bool) {
+	generateNeighbors(m, miners, n, mutual, m.rng)
+}
+
+func generateNeighbors(m Miner, miners []Miner, n int, mutual bool, rng *rand.Rand) {
+	known := map[string]bool{m.GetID(): true}
+	for _, i := range m.GetNeighbors() {
+		known[i.GetID()] = true
+	}
+	for _, i := range rng.Perm(len(miners)) {
+		if n == 0 {
+			break
+		}
+		if !known[miners[i].GetID()] {
+			m.AddNeighbor(miners[i], mutual)
+			n--
+		}
+	}
+}
+
+func (m *HonestMiner) SetNeighbors(n []Miner) {
+	m.neighbors = n
+}
+
+func (m *HonestMiner) GetNeighbors() []Miner {
+	return m.neighbors
+}
+
+func (m *HonestMiner) SetUnclePolicy(p *UnclePolicy) {
+	m.unclePolicy = p
+}
+
+// the fork choice is told about the blocks already on the miner's blockchain.
+func (m *HonestMiner) SetForkChoice(f ForkChoice) {
+	m.forkChoice = f
+	m.forkChoice.AddBlock(m.GetLastBlock())
+}
+
+func (m *HonestMiner) SetTieBreak(t TieBreak) {
+	m.tieBreak = t
+}
+
+func (m *HonestMiner) SetRetarget(r Retarget) {
+	m.retarget = r
+}
+
+// without a network, blocks reach neighbors immediately.
+func (m *HonestMiner) SetNetwork(n *Network) {
+	m.network = n
+}
+
+// the source of randomness of the miner's run, shared by every miner of the run.
+func (m *HonestMiner) SetRand(r *rand.Rand) {
+	m.rng = r
+}
+
+func (m *HonestMiner) AddNeighbor(n Miner, mutual bool) {
+	m.neighbors = append(m.neighbors, n)
+	if mutual {
+		n.AddNeighbor(m, false)
+	}
+}
+
+//one Tick is executed each round of the simulation.
+//each Tick contains a mining step, a communication step, and a block processing step.
+//each step is executed by all miners before beginning the next step.
+//these three steps were separated to avoid simulation biases stemming from
+// the order in which miners execute these steps.
+
+// attempts to find a block.
+// if found, the new block is added to the end of the miner's version of the blockchain,
+// then put on the block communication queue.
+func (m *HonestMiner) TickMine(totPower, timestamp, maxDepth, maxUncles int) {
+	block := m.Mine(totPower, timestamp, maxDepth, maxUncles)
+	if block != nil {
+		m.Mined(block)
+	}
+}
+
+// handles a block found by the miner: it extends the miner's chain and is shared with its neighbors.
+func (m *HonestMiner) Mined(b *Block) {
+	m.AppendBlock(b)
+	m.EnqueueBlock(b)
+}
+
+// pulls blocks from the publish queue, then shares each block with every immediate neighbor
+func (m *HonestMiner) TickCommunicate() {
+	blocks := m.publishQueue
+	m.publishQueue = []*Block{}
+	for _, b := range blocks {
+		m.PublishBlock(b)
+	}
+}
+
+// handle blocks received from neighbors during the current Tick
+func (m *HonestMiner) TickRead() {
+	for _, b := range m.GetReadQueue() {
+		m.ReceiveBlock(b)
+	}
+	m.ClearReadQueue()
+}
+
+func (m *HonestMiner) GetReadQueue() []*Block {
+	return m.readQueue
+}
+
+func (m *HonestMiner) ClearReadQueue() {
+	m.readQueue = []*Block{}
+}
+
+// miner makes an attempt to mine a new block.
+// new block contains fees based on time since last block in chain.
+// new block can up to maxUncles uncles, gaining extra rewards per uncle referenced.
+// uncles must not be more than maxDepth blocks old.
+// odds of finding a block depend on the difficulty of the next block.
+// tot_power and timestamp tracked in Simulate().
+func (m *HonestMiner) Mine(totPower, timestamp, maxDepth, maxUncles int) *Block {
+	if m.miningOdds(m.miningPower, timestamp) > m.rng.Float64() {
+		//timestamp randomized within timestamp+ticklength range to resolve "which block came first" conflicts.
+		return m.BlockFound(timestamp+m.rng.Intn(TICK_LENGTH-1), maxDepth, maxUncles)
+	}
+	return nil
+}
+
+// probability of the given mining power finding a block on the miner's chain during the Tick ending at timestamp
+func (m *HonestMiner) miningOdds(power, timestamp int) float64 {
+	return float64(power) * TICK_LENGTH / m.retarget.Difficulty(m.GetLastBlock(), timestamp)
+}
+
+// builds the block found at timestamp
+func (m *HonestMiner) BlockFound(timestamp, maxDepth, maxUncles int) *Block {
+	return m.mineBlock(m.id, func(id string) bool { return id == m.id }, timestamp, maxDepth, maxUncles)
+}
+
+// builds a new block on the miner's chain, credited to minerID.
+// own tells the uncle policy which miners' blocks count as the new block's miner's own.
+func (m *HonestMiner) mineBlock(minerID string, own func(string) bool, timestamp, maxDepth, maxUncles int) *Block {
+	parent := m.GetLastBlock()
+	newDepth := parent.depth + 1
+	candidates := []*Block{}
+	for id, i := range m.pendingUncles {
+		//uncles must be older than the new block, but not by more than maxDepth blocks.
+		//blocks too old already are dropped, so only recent blocks are visited; without uncles, every block is.
+		if maxUncles == 0 || newDepth-i.depth > maxDepth {
+			delete(m.pendingUncles, id)
+			continue
+		}
+		if i.depth >= newDepth {
+			continue
+		}
+		candidates = append(candidates, i)
+	}
+	//includedUncles was changed to map as a bodge to resolve duplicate uncles issue.
+	includedUncles := make(map[string]*Block)
+	for _, i := range m.unclePolicy.SelectUncles(own, candidates, maxUncles) {
+		includedUncles[i.GetID()] = i
+		m.IncludeUncle(i)
+	}
+	block := NewBlock(minerID, parent, includedUncles, timestamp)
+	block.setDifficulty(m.retarget.Difficulty(parent, block.timestamp))
+
+	block.markSeen(m)
+	return block
+}
+
+func (m *HonestMiner) PublishBlock(b *Block) {
+	if m.network != nil {
+		m.network.Publish(b)
+	}
+	for _, i := range m.neighbors {
+		if m.network != nil {
+			m.network.Send(m, i, b)
+		} else {
+			i.SendBlock(b)
+		}
+	}
+}
+
+func (m *HonestMiner) EnqueueBlock(b *Block) {
+	m.publishQueue = append(m.publishQueue, b)
+}
+
+func (m *HonestMiner) HasSeen(b *Block) bool {
+	return b.seenBy[m]
+}
+
+// called by sending block through reference to neighbor.
+// receiver's readQueue sorted by timestamp so blocks "discovered earlier" in the Tick are handled first.
+func (m *HonestMiner) SendBlock(b *Block) {
+	idx := len(m.readQueue)
+	for i, r := range m.readQueue {
+		if b.timestamp < r.timestamp {
+			idx = i
+			break
+		}
+	}
+	m.readQueue = append(m.readQueue, nil)
+	copy(m.readQueue[idx+1:], m.readQueue[idx:])
+	m.readQueue[idx] = b
+}
+
+//handler function for blocks in receive queue
+/*
+  1. check if block has been seen already; if yes return
+  2. add block to seen blocks
+    - also uncles
+    - and ancestors of uncles
+  3. if the fork choice rule does not prefer the new block's chain: add to pending uncles
+    - unless it is tied with the current block and the tie break policy prefers it
+  4. if the fork choice rule prefers the new block's chain:
+    - identify common ancestor
+    - move descendants of common ancestor (if any) to off-chain blocks
+    - append ancestors of new block and ancestors until common ancestor to main chain
+*/
+func (m *HonestMiner) ReceiveBlock(b *Block) {
+	if !m.RecordBlock(b) {
+		return
+	}
+	//append block to publish queue to share with rest of network.
+	m.EnqueueBlock(b)
+
+	currentBlock := m.GetLastBlock()
+	//blocks already on the miner's chain, received late, are neither uncles nor a reason to switch.
+	if b.IsAncestorOf(currentBlock) {
+		return
+	}
+	m.forkChoice.AddBlock(b)
+	for _, u := range b.sortedUncles() {
+		m.forkChoice.AddBlock(u)
+	}
+	//add to pending uncles, switching chain removes it again if it ends up on the chain.
+	m.AppendUncle(b)
+
+	//the fork choice rule decides whether to switch to the chain ending in the candidate.
+	candidate := m.forkChoice.Candidate(currentBlock, b)
+	preference := m.forkChoice.Compare(candidate, currentBlock)
+	if preference > 0 || (preference == 0 && m.tieBreak.Switch(m.id, currentBlock, candidate)) {
+		m.SwitchChain(candidate)
+	}
+}
+
+// marks a received block as seen and returns false if it had been seen already.
+func (m *HonestMiner) RecordBlock(b *Block) bool {
+	//check if block has been seen already.
+	if m.HasSeen(b) {
+		return false
+	}
+	//mark block as seen.
+	b.markSeen(m)
+
+	//also mark the block's uncle blocks and those uncles' ancestors as seen.
+	for _, uncle := range b.sortedUncles() {
+		found := false
+		for !found {
+			found = m.HasSeen(uncle)
+			uncle.markSeen(m)
+			m.IncludeUncle(uncle)
+			uncle = uncle.parent
+			if uncle == nil {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// makes b the last block of the miner's blockchain.
+// blocks after the common ancestor of b and the current last block are replaced by b and its ancestors.
+func (m *HonestMiner) SwitchChain(b *Block) {
+	o, n := m.GetLastBlock(), b
+	//find common ancestor.
+	oldFamily := []*Block{}
+	newFamily := []*Block{}
+	for true {
+		//find ancestors on same depth.
+		if n.depth > o.depth {
+			newFamily = append([]*Block{n}, newFamily...)
+			n = n.parent
+			continue
+		}
+		if o.depth > n.depth {
+			oldFamily = append([]*Block{o}, oldFamily...)
+			o = o.parent
+			continue
+		}
+		if !o.Equals(n) {
+			newFamily = append([]*Block{n}, newFamily...)
+			oldFamily = append([]*Block{o}, oldFamily...)
+			o = o.parent
+			n = n.parent
+			//update chain and remove pending uncles
+		} else {
+			m.AddBlocks(newFamily)
+			m.IncludeUncles(newFamily)
+			if len(oldFamily) > 0 {
+				m.RemoveBlocks(oldFamily)
+				m.IncludeUncles(oldFamily)
+			}
+			break
+		}
+	}
+}
+
+func (m *HonestMiner) GetBlockchain() []*Block {
+	return m.blockchain
+}
+
+func (m *HonestMiner) GetLastBlock() *Block {
+	chain := m.GetBlockchain()
+	return chain[len(chain)-1]
+}
+
+func (m *HonestMiner) AppendBlock(b *Block) {
+	m.blockchain = append(m.blockchain, b)
+	m.forkChoice.AddBlock(b)
+}
+
+func (m *HonestMiner) AddBlocks(b []*Block) {
+	for _, i := range b {
+		m.AppendBlock(i)
+	}
+}
+
+// blocks removed are usually near the end of the chain, so it is searched from the end.
+func (m *HonestMiner) RemoveBlock(b *Block) {
+	for idx := len(m.blockchain) - 1; idx >= 0; idx-- {
+		if m.blockchain[idx].Equals(b) {
+			m.blockchain = append(m.blockchain[:idx], m.blockchain[idx+1:]...)
+			return
+		}
+	}
+}
+
+func (m *HonestMiner) RemoveBlocks(b []*Block) {
+	for _, i := range b {
+		m.RemoveBlock(i)
+	}
+}
+
+func (m *HonestMiner) AppendUncle(b *Block) {
+	m.pendingUncles[b.GetID()] = b
+}
+
+func (m *HonestMiner) IncludeUncle(b *Block) {
+	delete(m.pendingUncles, b.GetID())
+}
+
+func (m *HonestMiner) IncludeUncles(b []*Block) {
+	for _, i := range b {
+		m.IncludeUncle(i)
+	}
+}
+
+func (m *HonestMiner) GetPendingUncles() map[string]*Block {
+	return m.pendingUncles
+}
+
+// rewards of the miner's chain, see chainGains.
+func (m *HonestMiner) CalculateGains(maxDepth int, uncleDivisor, nephewReward float64) map[string][]float64 {
+	return chainGains(m.GetLastBlock(), maxDepth, uncleDivisor, nephewReward)
+}
+
+// iterate through the block chain, starting with the tip and iterating through parents:
+// add fees from each block to the miner's total earnings
+func chainGains(tip *Block, maxDepth int, uncleDivisor, nephewReward float64) map[string][]float64 {
+	//declare variables
+	gains := make(map[string][]float64)
+	curBlock := tip
+	mid := ""
+	blockReward := 0.0
+	uid := ""
+	uncleDistance := 0
+	uncleReward := 0.0
+
+	//iterate through the blockchain, starting at last block
+	for curBlock.parent != nil {
+		//reset block reward tracker and add rewards in current block
+		blockReward = 0.0
+		blockReward += BLOCK_REWARD
+		blockReward += float64(curBlock.fees)
+		//add rewards from uncles
+		for _, u := range curBlock.sortedUncles() {
+			blockReward += float64(BLOCK_REWARD) * nephewReward
+
+			//also award uncle reward to uncle block miner
+			uid = u.minerID
+			uncleDistance = curBlock.depth - u.depth
+			uncleReward = math.Max(float64(BLOCK_REWARD)*(1-float64(uncleDistance)/float64(maxDepth)), 0)
+			uncleReward = uncleReward / uncleDivisor
+			if _, found := gains[uid]; found {
+				gains[uid][0] += uncleReward
+				gains[uid][2] += 1
+			} else {
+				gains[uid] = []float64{}
+				gains[uid] = append(gains[uid], uncleReward)
+				gains[uid] = append(gains[uid], 0)
+				gains[uid] = append(gains[uid], 1)
+			}
+		}
+		//award blockReward to current block's miner
+		mid = curBlock.minerID
+		if _, found := gains[mid]; found {
+			gains[mid][0] += blockReward
+			gains[mid][1] += 1
+		} else {
+			gains[mid] = []float64{}
+			gains[mid] = append(gains[mid], blockReward)
+			gains[mid] = append(gains[mid], 1)
+			gains[mid] = append(gains[mid], 0)
+		}
+		//update current block reference
+		curBlock = curBlock.parent
+	}
+	return gains
+}
@@ -0,0 +1,281 @@
+package sim
+
+import (
+	"container/heap"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	LATENCY_TICK        = "tick"
+	LATENCY_CONSTANT    = "constant"
+	LATENCY_EXPONENTIAL = "exponential"
+	LATENCY_MATRIX      = "matrix"
+)
+
+// Latency is the time a block takes to travel over a link, not counting the time to transmit it.
+type Latency interface {
+	GetName() string
+	//delay of a message sent from one miner to the other, sampled anew for every message
+	Delay(from, to string) int
+}
+
+// creates a latency model by name:
+//
+//	tick (default): no delay, a block sent in the communication step is read in the same Tick
+//	constant: every link takes mean time units
+//	exponential: delays are exponentially distributed with the given mean
+//	matrix: delays are read from a csv file with a header row of miner ids, then a row per miner starting with its id;
+//	links not in the file take mean time units
+func NewLatency(name string, mean int, file string, rng *rand.Rand) (Latency, error) {
+	if mean < 0 {
+		return nil, fmt.Errorf("latency %s: negative mean delay %d", name, mean)
+	}
+	switch name {
+	case "", LATENCY_TICK:
+		return &constantLatency{name: LATENCY_TICK}, nil
+	case LATENCY_CONSTANT:
+		return &constantLatency{name: LATENCY_CONSTANT, delay: mean}, nil
+	case LATENCY_EXPONENTIAL:
+		return &exponentialLatency{mean: mean, rng: rng}, nil
+	case LATENCY_MATRIX:
+		delays, err := readLatencyMatrix(file)
+		if err != nil {
+			return nil, fmt.Errorf("latency %s: %v", name, err)
+		}
+		return &matrixLatency{delays: delays, fallback: mean}, nil
+	}
+	return nil, fmt.Errorf("unknown latency model %q", name)
+}
+
+type constantLatency struct {
+	name  string
+	delay int
+}
+
+func (c *constantLatency) GetName() string {
+	return c.name
+}
+
+func (c *constantLatency) Delay(from, to string) int {
+	return c.delay
+}
+
+type exponentialLatency struct {
+	mean int
+	rng  *rand.Rand
+}
+
+func (e *exponentialLatency) GetName() string {
+	return LATENCY_EXPONENTIAL
+}
+
+func (e *exponentialLatency) Delay(from, to string) int {
+	return int(math.Round(e.rng.ExpFloat64() * float64(e.mean)))
+}
+
+type matrixLatency struct {
+	delays   map[string]map[string]int //sender id -> receiver id -> delay
+	fallback int
+}
+
+func (m *matrixLatency) GetName() string {
+	return LATENCY_MATRIX
+}
+
+func (m *matrixLatency) Delay(from, to string) int {
+	if d, found := m.delays[from][to]; found {
+		return d
+	}
+	return m.fallback
+}
+
+func readLatencyMatrix(path string) (map[string]map[string]int, error) {
+	cells, err := readMatrix(path)
+	if err != nil {
+		return nil, err
+	}
+	delays := make(map[string]map[string]int)
+	for from, row := range cells {
+		delays[from] = make(map[string]int)
+		for to, cell := range row {
+			d, err := strconv.Atoi(cell)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("%s: invalid delay %q from %s to %s", path, cell, from, to)
+			}
+			delays[from][to] = d
+		}
+	}
+	return delays, nil
+}
+
+// reads a csv file of bandwidths in kB per time unit between miners, in the format of the latency matrix.
+// a bandwidth of 0 is unlimited.
+func ReadBandwidthMatrix(path string) (map[string]map[string]float64, error) {
+	cells, err := readMatrix(path)
+	if err != nil {
+		return nil, err
+	}
+	bandwidths := make(map[string]map[string]float64)
+	for from, row := range cells {
+		bandwidths[from] = make(map[string]float64)
+		for to, cell := range row {
+			b, err := strconv.ParseFloat(cell, 64)
+			if err != nil || b < 0 {
+				return nil, fmt.Errorf("%s: invalid bandwidth %q from %s to %s", path, cell, from, to)
+			}
+			bandwidths[from][to] = b
+		}
+	}
+	return bandwidths, nil
+}
+
+// reads a csv file with a header row of miner ids, then a row per miner starting with its id,
+// into sender id -> receiver id -> cell.
+func readMatrix(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: no header row", path)
+	}
+	header := rows[0]
+	cells := make(map[string]map[string]string)
+	for _, row := range rows[1:] {
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("%s: row %q has %d fields, header has %d", path, row[0], len(row), len(header))
+		}
+		from := strings.TrimSpace(row[0])
+		cells[from] = make(map[string]string)
+		for i := 1; i < len(row); i++ {
+			cells[from][strings.TrimSpace(header[i])] = strings.TrimSpace(row[i])
+		}
+	}
+	return cells, nil
+}
+
+// Network carries blocks between neighbors.
+// a block arrives after the latency of the link plus the time to transmit it at the link's bandwidth;
+// it is read in the block processing step of the first Tick at or after its arrival.
+type Network struct {
+	latency   Latency
+	bandwidth float64                       //kB per time unit of links not in links, 0 for unlimited
+	links     map[string]map[string]float64 //sender id -> receiver id -> bandwidth of the link
+	blockSize float64
+	now       int
+	inFlight  map[int][]message //arrival time -> messages arriving then, in order of sending
+	arrivals  arrivals          //arrival times in inFlight
+	spare     [][]message       //emptied lists of inFlight, reused for later arrival times
+	observer  *Observer         //sees every block published, without latency
+}
+
+// bandwidth in kB per time unit, 0 for unlimited, of every link not in links, which may be nil; blockSize in kB.
+func NewNetwork(latency Latency, bandwidth float64, links map[string]map[string]float64, blockSize float64) *Network {
+	return &Network{latency: latency, bandwidth: bandwidth, links: links, blockSize: blockSize,
+		inFlight: make(map[int][]message)}
+}
+
+func (n *Network) GetLatency() Latency {
+	return n.latency
+}
+
+// current time of the simulation, set at the start of every Tick
+func (n *Network) SetTime(timestamp int) {
+	n.now = timestamp
+}
+
+func (n *Network) SetObserver(o *Observer) {
+	n.observer = o
+}
+
+// a block being published reaches the observer at once, before any neighbor.
+func (n *Network) Publish(b *Block) {
+	if n.observer != nil {
+		n.observer.Observe(b)
+	}
+}
+
+// time to transmit a block over the link from one miner to the other
+func (n *Network) transmit(from, to string) int {
+	bandwidth, found := n.links[from][to]
+	if !found {
+		bandwidth = n.bandwidth
+	}
+	if bandwidth <= 0 {
+		return 0
+	}
+	return int(math.Ceil(n.blockSize / bandwidth))
+}
+
+// a block the receiver has seen already is not sent, it would be ignored on arrival anyway.
+func (n *Network) Send(from, to Miner, b *Block) {
+	at := n.now + n.transmit(from.GetID(), to.GetID()) + n.latency.Delay(from.GetID(), to.GetID())
+	if to.HasSeen(b) {
+		return
+	}
+	messages, found := n.inFlight[at]
+	if !found {
+		heap.Push(&n.arrivals, at)
+		if len(n.spare) > 0 {
+			messages = n.spare[len(n.spare)-1]
+			n.spare = n.spare[:len(n.spare)-1]
+		}
+	}
+	n.inFlight[at] = append(messages, message{block: b, to: to})
+}
+
+// hands every block that has arrived by now to its receiver, in order of arrival, then of sending.
+func (n *Network) Deliver() {
+	for len(n.arrivals) > 0 && n.arrivals[0] <= n.now {
+		at := heap.Pop(&n.arrivals).(int)
+		messages := n.inFlight[at]
+		for i, m := range messages {
+			m.to.SendBlock(m.block)
+			messages[i] = message{}
+		}
+		delete(n.inFlight, at)
+		n.spare = append(n.spare, messages[:0])
+	}
+}
+
+type message struct {
+	block *Block
+	to    Miner
+}
+
+// min-heap of arrival times
+type arrivals []int
+
+func (a arrivals) Len() int {
+	return len(a)
+}
+
+func (a arrivals) Less(i, j int) bool {
+	return a[i] < a[j]
+}
+
+func (a arrivals) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+func (a *arrivals) Push(x interface{}) {
+	*a = append(*a, x.(int))
+}
+
+func (a *arrivals) Pop() interface{} {
+	old := *a
+	x := old[len(old)-1]
+	*a = old[:len(old)-1]
+	return x
+}
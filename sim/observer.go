@@ -0,0 +1,101 @@
+package sim
+
+// Observer sees every block the moment it is published, as a neighbor of every miner without latency would.
+// it keeps the whole block tree: the canonical chain, the uncles included in it, and stale blocks,
+// and follows the canonical chain by the fork choice rule, keeping to the block seen first among tied chains,
+// so it never favors selfish blocks.
+type Observer struct {
+	forkChoice ForkChoice
+	tip        *Block
+	blocks     map[string]*Block //id -> block
+	order      []*Block          //every block known, in the order seen
+}
+
+// forkChoice needs to be a fork choice of its own, not shared with any miner.
+func NewObserver(forkChoice ForkChoice) *Observer {
+	o := &Observer{forkChoice: forkChoice, blocks: make(map[string]*Block)}
+	o.tip = NewBlock("genesis", nil, nil, 0)
+	o.record(o.tip)
+	return o
+}
+
+// handles a published block; blocks seen already are ignored.
+func (o *Observer) Observe(b *Block) {
+	if !o.record(b) {
+		return
+	}
+	candidate := o.forkChoice.Candidate(o.tip, b)
+	if o.forkChoice.Compare(candidate, o.tip) > 0 {
+		o.tip = candidate
+	}
+}
+
+// adds the block to the tree after its unknown ancestors and uncles, which were never published themselves,
+// and returns false if it was known already.
+func (o *Observer) record(b *Block) bool {
+	if _, found := o.blocks[b.GetID()]; found {
+		return false
+	}
+	if b.parent != nil {
+		o.record(b.parent)
+	}
+	for _, u := range b.sortedUncles() {
+		o.record(u)
+	}
+	o.blocks[b.GetID()] = b
+	o.order = append(o.order, b)
+	o.forkChoice.AddBlock(b)
+	return true
+}
+
+// last block of the canonical chain
+func (o *Observer) GetLastBlock() *Block {
+	return o.tip
+}
+
+// canonical chain from genesis to the last block
+func (o *Observer) GetCanonicalChain() []*Block {
+	chain := make([]*Block, o.tip.depth+2)
+	for b := o.tip; b != nil; b = b.parent {
+		chain[b.depth+1] = b
+	}
+	return chain
+}
+
+// every block known, genesis first, then in the order seen; parents always come before their children.
+func (o *Observer) Blocks() []*Block {
+	return o.order
+}
+
+// blocks included as uncles by the canonical chain, in the order they are included.
+func (o *Observer) Uncles() []*Block {
+	uncles := []*Block{}
+	for _, b := range o.GetCanonicalChain() {
+		uncles = append(uncles, b.sortedUncles()...)
+	}
+	return uncles
+}
+
+// blocks neither on the canonical chain nor included as uncles by it, in the order seen.
+// blocks only referenced as uncles by other stale blocks are stale too, as they earn no reward.
+func (o *Observer) Stale() []*Block {
+	rewarded := make(map[string]bool)
+	for _, b := range o.GetCanonicalChain() {
+		rewarded[b.GetID()] = true
+	}
+	for _, u := range o.Uncles() {
+		rewarded[u.GetID()] = true
+	}
+	stale := []*Block{}
+	for _, b := range o.order {
+		if !rewarded[b.GetID()] {
+			stale = append(stale, b)
+		}
+	}
+	return stale
+}
+
+// rewards of the canonical chain, see chainGains.
+func (o *Observer) CalculateGains(maxDepth int, uncleDivisor, nephewReward float64) map[string][]float64 {
+	return chainGains(o.tip, maxDepth, uncleDivisor, nephewReward)
+}
@@ -0,0 +1,318 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/lordalek/dat650-project/metrics"
+)
+
+const (
+	FORMAT_TABLE = "table"
+	FORMAT_CSV   = "csv"
+	FORMAT_JSONL = "jsonl"
+	FORMAT_JSON  = "json"
+)
+
+// Record is the outcome of a run for a single miner, or pooled for a coalition of more than one miner.
+type Record struct {
+	Run         int
+	MinerID     string
+	Kind        string //"honest", "selfish" or "coalition"
+	Coalition   string //coalition of a selfish miner
+	Power       int
+	Rewards     float64
+	MainBlocks  float64
+	UncleBlocks float64
+	Neighbors   int     //at the start of the run
+	Eclipsed    int     //number of miners the adversary eclipses
+	PowerShare  float64 //fraction of the mining power of all miners
+	RewardShare float64 //fraction of the rewards of all miners
+}
+
+const (
+	KIND_HONEST    = "honest"
+	KIND_SELFISH   = "selfish"
+	KIND_COALITION = "coalition"
+)
+
+// a record per miner in the order of the miners, then one per coalition of more than one miner in order of id.
+func (r *Result) Records() []Record {
+	records := []Record{}
+	power := make(map[string]int)
+	for _, m := range r.Miners {
+		k := m.GetID()
+		rec := Record{Run: r.Run, MinerID: k, Kind: KIND_HONEST, Power: m.GetMiningPower(), Neighbors: r.Neighbors[k]}
+		if s, ok := m.(*SelfishMiner); ok {
+			rec.Kind = KIND_SELFISH
+			rec.Coalition = s.GetCoalition().GetID()
+			rec.Eclipsed = len(r.Eclipsed[k])
+			power[rec.Coalition] += rec.Power
+		}
+		if v, found := r.Gains[k]; found && len(v) >= 3 {
+			rec.Rewards, rec.MainBlocks, rec.UncleBlocks = v[0], v[1], v[2]
+		}
+		records = append(records, rec)
+	}
+	pooled := r.CoalitionGains()
+	ids := []string{}
+	for k := range pooled {
+		ids = append(ids, k)
+	}
+	sort.Strings(ids)
+	for _, k := range ids {
+		v := pooled[k]
+		records = append(records, Record{Run: r.Run, MinerID: k, Kind: KIND_COALITION, Coalition: k, Power: power[k],
+			Rewards: v[0], MainBlocks: v[1], UncleBlocks: v[2]})
+	}
+	totPower, totRewards := 0.0, 0.0
+	for _, rec := range records[:len(r.Miners)] {
+		totPower += float64(rec.Power)
+		totRewards += rec.Rewards
+	}
+	for i := range records {
+		if totPower > 0 {
+			records[i].PowerShare = float64(records[i].Power) / totPower
+		}
+		if totRewards > 0 {
+			records[i].RewardShare = records[i].Rewards / totRewards
+		}
+	}
+	return records
+}
+
+// fairness of the rewards of the run's miners, given their mining power at the end of the run.
+func (r *Result) Fairness() metrics.Fairness {
+	power, rewards := []float64{}, []float64{}
+	for _, m := range r.Miners {
+		reward := 0.0
+		if v, found := r.Gains[m.GetID()]; found && len(v) > 0 {
+			reward = v[0]
+		}
+		power = append(power, float64(m.GetMiningPower()))
+		rewards = append(rewards, reward)
+	}
+	return metrics.Compute(power, rewards)
+}
+
+// fairness over a number of runs, each miner's mining power and rewards added up over all runs.
+func PooledFairness(results []*Result) metrics.Fairness {
+	ids := []string{}
+	power, rewards := make(map[string]float64), make(map[string]float64)
+	for _, r := range results {
+		for _, m := range r.Miners {
+			k := m.GetID()
+			if _, found := power[k]; !found {
+				ids = append(ids, k)
+			}
+			power[k] += float64(m.GetMiningPower())
+			if v, found := r.Gains[k]; found && len(v) > 0 {
+				rewards[k] += v[0]
+			}
+		}
+	}
+	p, rw := make([]float64, len(ids)), make([]float64, len(ids))
+	for i, k := range ids {
+		p[i], rw[i] = power[k], rewards[k]
+	}
+	return metrics.Compute(p, rw)
+}
+
+// writes the results in the given format:
+//
+//	table: the simulator's original output, see WriteTable
+//	csv: a row per record, starting with the run and every config field, see WriteCSV
+//	jsonl: a record per line
+//	json: a single document holding the config, and the fairness and records of every run
+func WriteResults(w io.Writer, format string, conf Config, results []*Result) error {
+	switch format {
+	case FORMAT_TABLE:
+		return WriteTable(w, results)
+	case FORMAT_CSV:
+		return WriteCSV(w, conf, results)
+	case FORMAT_JSONL:
+		return WriteJSONL(w, results)
+	case FORMAT_JSON:
+		return WriteJSON(w, conf, results)
+	}
+	return fmt.Errorf("unknown results format %q", format)
+}
+
+// writes the results to the file at path, through a temporary file in the same directory,
+// so the file is either complete or not there at all.
+func WriteResultsFile(path, format string, conf Config, results []*Result) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	//temporary files are owner-only, results are as readable as those written with os.Create.
+	err = tmp.Chmod(0644)
+	if err == nil {
+		err = WriteResults(tmp, format, conf, results)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// WriteCSV writes a header, then a row per record of every run: the run, every config field, the record,
+// then the fairness of the run, so rows from different configs and runs can be put in one table.
+func WriteCSV(out io.Writer, conf Config, results []*Result) error {
+	w := csv.NewWriter(out)
+	names, values := ConfigFields(conf)
+	header := append([]string{"run"}, names...)
+	header = append(header, "minerID", "kind", "coalition", "power", "rewards_gained", "main_blocks_created",
+		"uncle_blocks_created", "neighbors", "eclipsed", "power_share", "reward_share",
+		"gini", "jain", "theil", "slope")
+	w.Write(header)
+	for _, result := range results {
+		f := result.Fairness()
+		for _, r := range result.Records() {
+			row := append([]string{strconv.Itoa(r.Run)}, values...)
+			row = append(row, r.MinerID, r.Kind, r.Coalition, strconv.Itoa(r.Power),
+				fmt.Sprintf("%f", r.Rewards), fmt.Sprintf("%f", r.MainBlocks), fmt.Sprintf("%f", r.UncleBlocks),
+				strconv.Itoa(r.Neighbors), strconv.Itoa(r.Eclipsed),
+				fmt.Sprintf("%f", r.PowerShare), fmt.Sprintf("%f", r.RewardShare),
+				fmt.Sprintf("%f", f.Gini), fmt.Sprintf("%f", f.Jain), fmt.Sprintf("%f", f.Theil), fmt.Sprintf("%f", f.Slope))
+			w.Write(row)
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// WriteJSONL writes every record of every run as a json object on a line of its own.
+func WriteJSONL(out io.Writer, results []*Result) error {
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for _, result := range results {
+		for _, r := range result.Records() {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// WriteJSON writes a single json document with the config, the fairness over all runs and, per run,
+// its fairness and records.
+func WriteJSON(out io.Writer, conf Config, results []*Result) error {
+	type run struct {
+		Run      int
+		Fairness metrics.Fairness
+		Records  []Record
+	}
+	doc := struct {
+		Config   Config
+		Fairness metrics.Fairness
+		Runs     []run
+	}{Config: conf, Fairness: PooledFairness(results), Runs: []run{}}
+	for _, result := range results {
+		doc.Runs = append(doc.Runs, run{Run: result.Run, Fairness: result.Fairness(), Records: result.Records()})
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	return enc.Encode(doc)
+}
+
+// names and values of the config fields in order, for use as csv columns.
+// lists such as Adversaries are given as json, or empty if there are none.
+func ConfigFields(conf Config) ([]string, []string) {
+	names, values := []string{}, []string{}
+	t, v := reflect.TypeOf(conf), reflect.ValueOf(conf)
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, t.Field(i).Name)
+		f := v.Field(i)
+		value := fmt.Sprint(f.Interface())
+		if f.Kind() == reflect.Slice {
+			value = ""
+			if f.Len() > 0 {
+				data, _ := json.Marshal(f.Interface())
+				value = string(data)
+			}
+		}
+		values = append(values, value)
+	}
+	return names, values
+}
+
+// WriteTable writes the results of a number of runs in the simulator's original output format:
+// for every run a minerID header, a row per miner, then pooled rows for coalitions of more than one miner.
+// runs with adversaries add an adversaryID header, and a row per adversary with its network position
+// next to its share of the mining power and of the rewards.
+func WriteTable(out io.Writer, results []*Result) error {
+	w := bufio.NewWriter(out)
+	for _, result := range results {
+		fmt.Fprintln(w, "minerID,power,rewards_gained,main_blocks_created,uncle_blocks_created")
+		for _, m := range result.Miners {
+			k := m.GetID()
+			v, found := result.Gains[k]
+			if found {
+				if len(v) >= 3 {
+					fmt.Fprintf(w, "%s,%d,%f,%f,%f\n", k, m.GetMiningPower(), v[0], v[1], v[2])
+				}
+			} else {
+				fmt.Fprintf(w, "%s,%d,%f,%f,%f\n", k, m.GetMiningPower(), 0.0, 0.0, 0.0)
+			}
+		}
+		//pooled gains of colluding selfish miners.
+		power := make(map[string]int)
+		for _, m := range result.Miners {
+			if s, ok := m.(*SelfishMiner); ok {
+				power[s.GetCoalition().GetID()] += m.GetMiningPower()
+			}
+		}
+		pooled := result.CoalitionGains()
+		ids := []string{}
+		for k := range pooled {
+			ids = append(ids, k)
+		}
+		sort.Strings(ids)
+		for _, k := range ids {
+			v := pooled[k]
+			fmt.Fprintf(w, "%s,%d,%f,%f,%f\n", k, power[k], v[0], v[1], v[2])
+		}
+
+		if len(result.Eclipsed) == 0 {
+			continue
+		}
+		totPower, totRewards := 0, 0.0
+		for _, m := range result.Miners {
+			totPower += m.GetMiningPower()
+			if v, found := result.Gains[m.GetID()]; found {
+				totRewards += v[0]
+			}
+		}
+		fmt.Fprintln(w, "adversaryID,neighbors,eclipsed,power_share,reward_share")
+		for _, m := range result.Miners {
+			k := m.GetID()
+			if _, ok := m.(*SelfishMiner); !ok {
+				continue
+			}
+			//a run without rewards gives every adversary a reward share of 0.
+			rewardShare := 0.0
+			if v, found := result.Gains[k]; found && totRewards > 0 {
+				rewardShare = v[0] / totRewards
+			}
+			fmt.Fprintf(w, "%s,%d,%d,%f,%f\n", k, result.Neighbors[k], len(result.Eclipsed[k]),
+				float64(m.GetMiningPower())/float64(totPower), rewardShare)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,92 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// gives the adversaries the links asked for in their config, on top of those of the topology.
+// miners is in slot order; adversaries[i] is the config of the adversary with id adversaries[i].ID.
+// eclipsed victims lose every other neighbor, so all blocks they send and receive pass through their eclipsers.
+func positionAdversaries(miners []Miner, adversaries []Adversary, rng *rand.Rand) error {
+	byID := make(map[string]Miner)
+	for _, m := range miners {
+		byID[m.GetID()] = m
+	}
+	honest := []Miner{}
+	for _, m := range miners {
+		if _, selfish := m.(*SelfishMiner); !selfish {
+			honest = append(honest, m)
+		}
+	}
+	//most mining power first, ties in slot order.
+	top := append([]Miner{}, honest...)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].GetMiningPower() > top[j].GetMiningPower()
+	})
+
+	eclipsers := make(map[string][]Miner) //victim id -> adversaries eclipsing it
+	victims := []string{}
+	for _, a := range adversaries {
+		adv := byID[a.ID]
+		if a.ExtraLinks < 0 || a.TopLinks < 0 {
+			return fmt.Errorf("adversary %s: negative number of links", a.ID)
+		}
+		for _, i := range rng.Perm(len(miners)) {
+			if a.ExtraLinks == 0 {
+				break
+			}
+			if link(adv, miners[i]) {
+				a.ExtraLinks--
+			}
+		}
+		for i := 0; i < a.TopLinks && i < len(top); i++ {
+			link(adv, top[i])
+		}
+		for _, v := range a.Eclipse {
+			victim, found := byID[v]
+			if !found {
+				return fmt.Errorf("adversary %s: unknown eclipse victim %q", a.ID, v)
+			}
+			if _, selfish := victim.(*SelfishMiner); selfish {
+				return fmt.Errorf("adversary %s: eclipse victim %s is an adversary", a.ID, v)
+			}
+			if len(eclipsers[v]) == 0 {
+				victims = append(victims, v)
+			}
+			eclipsers[v] = append(eclipsers[v], adv)
+		}
+	}
+
+	for _, v := range victims {
+		for _, m := range miners {
+			neighbors := []Miner{}
+			for _, n := range m.GetNeighbors() {
+				if n.GetID() != v {
+					neighbors = append(neighbors, n)
+				}
+			}
+			m.SetNeighbors(neighbors)
+		}
+		byID[v].SetNeighbors([]Miner{})
+		for _, adv := range eclipsers[v] {
+			link(adv, byID[v])
+		}
+	}
+	return nil
+}
+
+// makes a and b each other's neighbors, unless they are the same miner or neighbors already.
+func link(a, b Miner) bool {
+	if a.GetID() == b.GetID() {
+		return false
+	}
+	for _, n := range a.GetNeighbors() {
+		if n.GetID() == b.GetID() {
+			return false
+		}
+	}
+	a.AddNeighbor(b, true)
+	return true
+}
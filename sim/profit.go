@@ -0,0 +1,158 @@
+package sim
+
+import (
+	"fmt"
+)
+
+// Profit compares what an adversary earns to what it would earn by mining honestly.
+// an adversary is a selfish miner on its own, or a coalition of more than one selfish miner.
+type Profit struct {
+	Run             int //-1 for the pooled profit of a number of runs
+	ID              string
+	Rewards         float64
+	RelativeRevenue float64 //fraction of the rewards of all miners
+	FairShare       float64 //fraction of the mining power of all miners, the relative revenue of honest mining
+	Ratio           float64 //relative revenue over fair share, mining selfishly is profitable above 1
+	RevenuePerTime  float64 //rewards per time unit
+}
+
+// profit of every adversary in the run, in the order of Records.
+func (r *Result) Profits() []Profit {
+	profits := []Profit{}
+	for _, rec := range r.Records() {
+		pooled := len(r.Coalitions[rec.Coalition]) > 1
+		if (rec.Kind == KIND_SELFISH && !pooled) || rec.Kind == KIND_COALITION {
+			profits = append(profits, newProfit(r.Run, rec.MinerID, rec.Rewards, rec.RewardShare, rec.PowerShare, r.Time))
+		}
+	}
+	return profits
+}
+
+// profit of every adversary over a number of runs: its rewards, and those of all miners, added up over the runs,
+// and its fair share averaged over the runs.
+func PooledProfits(results []*Result) []Profit {
+	if len(results) == 0 {
+		return []Profit{}
+	}
+	ids := []string{}
+	rewards, share := make(map[string]float64), make(map[string]float64)
+	totRewards, totTime := 0.0, 0
+	for _, r := range results {
+		for _, rec := range r.Records() {
+			if rec.Kind != KIND_COALITION {
+				totRewards += rec.Rewards
+			}
+		}
+		for _, p := range r.Profits() {
+			if _, found := rewards[p.ID]; !found {
+				ids = append(ids, p.ID)
+			}
+			rewards[p.ID] += p.Rewards
+			share[p.ID] += p.FairShare / float64(len(results))
+		}
+		totTime += r.Time
+	}
+	profits := []Profit{}
+	for _, id := range ids {
+		relative := 0.0
+		if totRewards > 0 {
+			relative = rewards[id] / totRewards
+		}
+		profits = append(profits, newProfit(-1, id, rewards[id], relative, share[id], totTime))
+	}
+	return profits
+}
+
+func newProfit(run int, id string, rewards, relative, fair float64, time int) Profit {
+	p := Profit{Run: run, ID: id, Rewards: rewards, RelativeRevenue: relative, FairShare: fair}
+	if fair > 0 {
+		p.Ratio = relative / fair
+	}
+	if time > 0 {
+		p.RevenuePerTime = rewards / float64(time)
+	}
+	return p
+}
+
+// Threshold is the least mining power at which an adversary profits from its strategy.
+type Threshold struct {
+	Power  float64 //SelfishPower, or the Power of the first adversary
+	Profit Profit  //pooled profit at that power
+	Steps  int     //number of powers simulated
+}
+
+// ProfitabilityThreshold bisects the mining power of the adversary for the least power at which its pooled ratio,
+// over conf.Runs runs, is above 1. the adversary is the first of conf.Adversaries, or else the single adversary
+// set up by the Selfish* fields. the search stops once the interval is narrower than tolerance; as the power is
+// a percentile of the honest miners, intervals narrower than 1/Miners do not tell powers apart.
+// the ratio is assumed to grow with the power; noise from too few runs may make the search settle on a wrong power.
+// with a PowerScaling of 1 every miner has the same mining power, whatever the percentile, so that is an error.
+func ProfitabilityThreshold(conf Config, tolerance float64, workers int) (Threshold, error) {
+	if tolerance <= 0 {
+		return Threshold{}, fmt.Errorf("tolerance %v, must be positive", tolerance)
+	}
+	if conf.PowerScaling <= 1 {
+		return Threshold{}, fmt.Errorf("PowerScaling is %v, must be above 1 for the adversary's power to vary", conf.PowerScaling)
+	}
+	if len(conf.Adversaries) == 0 {
+		conf.SelfishMiners = 1
+	}
+	steps := 0
+	profit := func(power float64) (Profit, error) {
+		c := conf
+		if len(c.Adversaries) > 0 {
+			c.Adversaries = append([]Adversary{}, conf.Adversaries...)
+			c.Adversaries[0].Power = power
+		} else {
+			c.SelfishPower = power
+		}
+		if err := c.Validate(); err != nil {
+			return Profit{}, err
+		}
+		adversaries, err := c.GetAdversaries()
+		if err != nil {
+			return Profit{}, err
+		}
+		results, err := SimulateRuns(c, workers)
+		if err != nil {
+			return Profit{}, fmt.Errorf("power %v: %v", power, err)
+		}
+		steps++
+		for _, p := range PooledProfits(results) {
+			if p.ID == adversaries[0].Coalition {
+				return p, nil
+			}
+		}
+		return Profit{}, fmt.Errorf("power %v: no profit for adversary %s", power, adversaries[0].Coalition)
+	}
+
+	//the powers are kept within (0,1), on the grid of percentiles of the honest miners.
+	lo, hi := 0.5/float64(conf.Miners), 1-0.5/float64(conf.Miners)
+	best, err := profit(hi)
+	if err != nil {
+		return Threshold{}, err
+	}
+	if best.Ratio <= 1 {
+		return Threshold{}, fmt.Errorf("not profitable at power %v, ratio %f", hi, best.Ratio)
+	}
+	low, err := profit(lo)
+	if err != nil {
+		return Threshold{}, err
+	}
+	if low.Ratio > 1 {
+		return Threshold{Power: lo, Profit: low, Steps: steps}, nil
+	}
+	for hi-lo > tolerance {
+		mid := (lo + hi) / 2
+		p, err := profit(mid)
+		if err != nil {
+			return Threshold{}, err
+		}
+		if p.Ratio > 1 {
+			hi, best = mid, p
+		} else {
+			lo = mid
+		}
+	}
+	return Threshold{Power: hi, Profit: best, Steps: steps}, nil
+}
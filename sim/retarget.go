@@ -0,0 +1,120 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	RETARGET_NONE      = "none"
+	RETARGET_HOMESTEAD = "homestead"
+	RETARGET_ETHEREUM  = "ethereum"
+	RETARGET_BITCOIN   = "bitcoin"
+)
+
+// Retarget is a difficulty adjustment algorithm.
+// a miner with mining power p finds a block of difficulty d with probability p*TICK_LENGTH/d per Tick,
+// so the expected time between blocks is the difficulty divided by the total mining power.
+type Retarget interface {
+	GetName() string
+	//difficulty of a block mined on parent at timestamp
+	Difficulty(parent *Block, timestamp int) float64
+}
+
+// creates a difficulty adjustment algorithm by name:
+//
+//	none (default): every block has the initial difficulty
+//	homestead: Ethereum's per-block adjustment from EIP-2
+//	ethereum: Ethereum's per-block adjustment from EIP-100, aiming for a steady rate of blocks including uncles
+//	bitcoin: every interval blocks, scale the difficulty by how far the last interval was off BLOCK_TIME per block
+//
+// blocks mined on genesis get the initial difficulty.
+func NewRetarget(name string, initial float64, interval int) (Retarget, error) {
+	switch name {
+	case "", RETARGET_NONE:
+		return &constantDifficulty{initial: initial}, nil
+	case RETARGET_HOMESTEAD:
+		return &ethereumDifficulty{initial: initial}, nil
+	case RETARGET_ETHEREUM:
+		return &ethereumDifficulty{initial: initial, uncleAware: true}, nil
+	case RETARGET_BITCOIN:
+		if interval < 1 {
+			return nil, fmt.Errorf("retarget %s: interval must be at least 1, got %d", name, interval)
+		}
+		return &bitcoinDifficulty{initial: initial, interval: interval}, nil
+	}
+	return nil, fmt.Errorf("unknown difficulty adjustment %q", name)
+}
+
+// difficulty making the given total mining power find a block every BLOCK_TIME on average
+func InitialDifficulty(totPower int) float64 {
+	return float64(totPower) * BLOCK_TIME
+}
+
+type constantDifficulty struct {
+	initial float64
+}
+
+func (c *constantDifficulty) GetName() string {
+	return RETARGET_NONE
+}
+
+func (c *constantDifficulty) Difficulty(parent *Block, timestamp int) float64 {
+	return c.initial
+}
+
+// Ethereum scales the parent's difficulty by 1/2048 per step,
+// with a step for every timeBucket the block is later than the parent.
+// Ethereum uses buckets of 9 or 10 seconds for a block time of about 14 seconds, scaled here to BLOCK_TIME.
+// with uncleAware, parents referencing uncles count as one more step, as in EIP-100.
+type ethereumDifficulty struct {
+	initial    float64
+	uncleAware bool
+}
+
+func (e *ethereumDifficulty) GetName() string {
+	if e.uncleAware {
+		return RETARGET_ETHEREUM
+	}
+	return RETARGET_HOMESTEAD
+}
+
+func (e *ethereumDifficulty) Difficulty(parent *Block, timestamp int) float64 {
+	if parent.parent == nil {
+		return e.initial
+	}
+	timeBucket := BLOCK_TIME * 2 / 3
+	steps := 1.0
+	if e.uncleAware && len(parent.uncles) > 0 {
+		steps = 2
+	}
+	steps = math.Max(steps-math.Floor(float64(timestamp-parent.timestamp)/timeBucket), -99)
+	return math.Max(parent.difficulty+parent.difficulty/2048*steps, 1)
+}
+
+// Bitcoin keeps the difficulty for interval blocks, then scales it by at most a factor 4 either way.
+type bitcoinDifficulty struct {
+	initial  float64
+	interval int
+}
+
+func (b *bitcoinDifficulty) GetName() string {
+	return RETARGET_BITCOIN
+}
+
+func (b *bitcoinDifficulty) Difficulty(parent *Block, timestamp int) float64 {
+	if parent.parent == nil {
+		return b.initial
+	}
+	if (parent.depth+1)%b.interval != 0 {
+		return parent.difficulty
+	}
+	first := parent
+	for parent.depth-first.depth < b.interval && first.parent != nil {
+		first = first.parent
+	}
+	actual := math.Max(float64(parent.timestamp-first.timestamp), 1)
+	factor := BLOCK_TIME * float64(parent.depth-first.depth) / actual
+	factor = math.Min(math.Max(factor, 0.25), 4)
+	return parent.difficulty * factor
+}
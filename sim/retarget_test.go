@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestEthereumDifficulty(t *testing.T) {
+	genesis := NewBlock("genesis", nil, nil, 0)
+	first := NewBlock("m", genesis, nil, 500)
+	first.setDifficulty(2048)
+	uncle := NewBlock("u", genesis, nil, 600)
+	withUncle := NewBlock("m", genesis, map[string]*Block{uncle.GetID(): uncle}, 500)
+	withUncle.setDifficulty(2048)
+	easiest := NewBlock("m", genesis, nil, 500)
+
+	//a step of 1/2048 of the parent's difficulty for every 333.3 the block is later than the parent, starting at +1.
+	tests := []struct {
+		retarget string
+		parent   *Block
+		delay    int
+		want     float64
+	}{
+		{RETARGET_HOMESTEAD, genesis, 0, 100}, //the initial difficulty
+		{RETARGET_HOMESTEAD, first, 0, 2049},
+		{RETARGET_HOMESTEAD, first, 333, 2049},
+		{RETARGET_HOMESTEAD, first, 334, 2048},
+		{RETARGET_HOMESTEAD, first, 1000, 2046},
+		{RETARGET_HOMESTEAD, first, 100000, 2048 - 99}, //at most 99 steps down
+		{RETARGET_HOMESTEAD, withUncle, 0, 2049},
+		{RETARGET_HOMESTEAD, easiest, 100000, 1}, //never below 1
+		{RETARGET_ETHEREUM, first, 0, 2049},
+		{RETARGET_ETHEREUM, withUncle, 0, 2050}, //uncles count as one more step
+		{RETARGET_ETHEREUM, withUncle, 334, 2049},
+		{RETARGET_ETHEREUM, withUncle, 1000, 2047},
+	}
+	for _, tt := range tests {
+		r, err := NewRetarget(tt.retarget, 100, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Difficulty(tt.parent, tt.parent.GetTimestamp()+tt.delay); got != tt.want {
+			t.Errorf("%s on %s after %d: difficulty %v, want %v", tt.retarget, tt.parent.GetID(), tt.delay, got, tt.want)
+		}
+	}
+}
+
+func TestBitcoinDifficulty(t *testing.T) {
+	//blocks at depths 0 to 7 of difficulty 1000, spacing apart, so the retarget for depth 8 covers depths 3 to 7.
+	chain := func(spacing int) []*Block {
+		blocks := []*Block{}
+		parent := NewBlock("genesis", nil, nil, 0)
+		for i := 0; i < 8; i++ {
+			parent = NewBlock("m", parent, nil, (i+1)*spacing)
+			parent.setDifficulty(1000)
+			blocks = append(blocks, parent)
+		}
+		return blocks
+	}
+
+	tests := []struct {
+		spacing int
+		depth   int //of the new block
+		want    float64
+	}{
+		{BLOCK_TIME, 8, 1000},
+		{BLOCK_TIME / 2, 8, 2000},
+		{BLOCK_TIME * 2, 8, 500},
+		{BLOCK_TIME / 5, 8, 4000}, //5 times too fast, clamped to 4
+		{BLOCK_TIME * 10, 8, 250}, //10 times too slow, clamped to 1/4
+		{BLOCK_TIME / 5, 6, 1000}, //only at period boundaries
+		{BLOCK_TIME / 5, 4, 4000}, //the first period
+	}
+	r, err := NewRetarget(RETARGET_BITCOIN, 100, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		parent := chain(tt.spacing)[tt.depth-1]
+		if got := r.Difficulty(parent, parent.GetTimestamp()+tt.spacing); got != tt.want {
+			t.Errorf("block at depth %d, %d apart: difficulty %v, want %v", tt.depth, tt.spacing, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	POWER_JOIN   = "join"
+	POWER_LEAVE  = "leave"
+	POWER_CHANGE = "power"
+)
+
+// PowerEvent changes the mining power of a miner during a run.
+// a miner whose first event is a join is offline until then; joining with an id not used by any miner adds a new honest miner.
+// offline miners neither mine nor communicate, blocks sent to them are lost.
+type PowerEvent struct {
+	Time   int    //timestamp the event takes effect, applied at the start of the first Tick at or after it
+	Miner  string //id of the miner, e.g. m3 or s0
+	Action string //"join", "leave" or "power"
+	Power  int    //mining power from then on; a miner rejoining with power 0 keeps its former power
+}
+
+// returns the events sorted by time, events with the same time kept in the given order.
+func sortedSchedule(events []PowerEvent) ([]PowerEvent, error) {
+	sorted := append([]PowerEvent{}, events...)
+	for _, e := range sorted {
+		switch {
+		case e.Action != POWER_JOIN && e.Action != POWER_LEAVE && e.Action != POWER_CHANGE:
+			return nil, fmt.Errorf("unknown power event action %q", e.Action)
+		case e.Miner == "":
+			return nil, fmt.Errorf("power event at %d: missing miner id", e.Time)
+		case e.Power < 0:
+			return nil, fmt.Errorf("power event at %d: negative mining power %d for %s", e.Time, e.Power, e.Miner)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time < sorted[j].Time
+	})
+	return sorted, nil
+}
+
+// sum of the mining power of the miners that are online
+func onlineMiningPower(miners []Miner, offline map[string]bool) int {
+	total := 0
+	for _, m := range miners {
+		if !offline[m.GetID()] {
+			total += m.GetMiningPower()
+		}
+	}
+	return total
+}
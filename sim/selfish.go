@@ -0,0 +1,215 @@
+package sim
+
+import (
+	"math/rand"
+)
+
+// the wrapped miner handles the selfish miner's own mining power and network connections,
+// while chain state and publishing decisions are shared with the rest of its coalition.
+type SelfishMiner struct {
+	miner     Miner
+	coalition *Coalition
+	rng       *rand.Rand
+}
+
+// initialize selfish miner: contains regular miner, joins the given coalition
+func NewSelfishMiner(name string, neighbors []Miner, mining_power int, coalition *Coalition) Miner {
+	s := &SelfishMiner{miner: NewMiner(name, neighbors, mining_power, 0), coalition: coalition, rng: rand.New(rand.NewSource(1))}
+	coalition.AddMember(s)
+	return s
+}
+
+func (s *SelfishMiner) GetCoalition() *Coalition {
+	return s.coalition
+}
+
+func (s *SelfishMiner) GetStrategy() Strategy {
+	return s.coalition.GetStrategy()
+}
+
+func (s *SelfishMiner) GetID() string {
+	return s.miner.GetID()
+}
+
+func (s *SelfishMiner) GetMiningPower() int {
+	return s.miner.GetMiningPower()
+}
+
+func (s *SelfishMiner) SetMiningPower(p int) {
+	s.miner.SetMiningPower(p)
+}
+
+func (s *SelfishMiner) GenerateNeighbors(m []Miner, n int, mutual bool) {
+	generateNeighbors(s, m, n, mutual, s.rng)
+}
+
+func (s *SelfishMiner) SetNeighbors(n []Miner) {
+	s.miner.SetNeighbors(n)
+}
+
+func (s *SelfishMiner) GetNeighbors() []Miner {
+	return s.miner.GetNeighbors()
+}
+
+func (s *SelfishMiner) SetUnclePolicy(p *UnclePolicy) {
+	s.coalition.unclePolicy = p
+	s.coalition.chain.SetUnclePolicy(p)
+}
+
+func (s *SelfishMiner) SetForkChoice(f ForkChoice) {
+	s.coalition.SetForkChoice(f)
+}
+
+// selfish miners do not break ties, their strategy decides which tip to mine on.
+func (s *SelfishMiner) SetTieBreak(t TieBreak) {
+	s.coalition.chain.SetTieBreak(t)
+}
+
+func (s *SelfishMiner) SetRetarget(r Retarget) {
+	s.coalition.chain.SetRetarget(r)
+}
+
+func (s *SelfishMiner) SetNetwork(n *Network) {
+	s.miner.SetNetwork(n)
+}
+
+func (s *SelfishMiner) SetRand(r *rand.Rand) {
+	s.rng = r
+	s.miner.SetRand(r)
+	s.coalition.chain.SetRand(r)
+}
+
+// the neighbor sends blocks to the selfish miner, not to the wrapped miner.
+func (s *SelfishMiner) AddNeighbor(n Miner, mutual bool) {
+	s.miner.AddNeighbor(n, false)
+	if mutual {
+		n.AddNeighbor(s, false)
+	}
+}
+
+// encapsulates current extent of the selfish behavior.
+// when selfish miner mines a new block, instead of publishing immediately,
+// the block is withheld on the private chain until the strategy decides to publish it.
+func (s *SelfishMiner) TickMine(totPower, timestamp, maxDepth, maxUncles int) {
+	block := s.Mine(totPower, timestamp, maxDepth, maxUncles)
+	if block != nil {
+		s.Mined(block)
+	}
+}
+
+func (s *SelfishMiner) Mined(b *Block) {
+	s.coalition.mined(b)
+}
+
+// publishes blocks released by the strategy this Tick, along with blocks relayed for the rest of the network
+func (s *SelfishMiner) TickCommunicate() {
+	s.coalition.tick(s.GetID())
+	s.miner.TickCommunicate()
+}
+
+// received blocks are handled by the selfish miner rather than the wrapped miner,
+// so the coalition can react to the honest network extending the public chain.
+func (s *SelfishMiner) TickRead() {
+	for _, b := range s.GetReadQueue() {
+		s.ReceiveBlock(b)
+	}
+	s.ClearReadQueue()
+}
+
+func (s *SelfishMiner) GetReadQueue() []*Block {
+	return s.miner.GetReadQueue()
+}
+
+func (s *SelfishMiner) ClearReadQueue() {
+	s.miner.ClearReadQueue()
+}
+
+// mining power is the selfish miner's own, the block extends the coalition's private chain.
+func (s *SelfishMiner) Mine(totPower, timestamp, maxDepth, maxUncles int) *Block {
+	if s.coalition.chain.miningOdds(s.GetMiningPower(), timestamp) > s.rng.Float64() {
+		return s.BlockFound(timestamp+s.rng.Intn(TICK_LENGTH-1), maxDepth, maxUncles)
+	}
+	return nil
+}
+
+func (s *SelfishMiner) BlockFound(timestamp, maxDepth, maxUncles int) *Block {
+	return s.coalition.blockFound(s.GetID(), timestamp, maxDepth, maxUncles)
+}
+
+func (s *SelfishMiner) PublishBlock(b *Block) {
+	s.miner.PublishBlock(b)
+}
+
+func (s *SelfishMiner) EnqueueBlock(b *Block) {
+	s.miner.EnqueueBlock(b)
+}
+
+func (s *SelfishMiner) HasSeen(b *Block) bool {
+	return s.coalition.chain.HasSeen(b)
+}
+
+func (s *SelfishMiner) SendBlock(b *Block) {
+	s.miner.SendBlock(b)
+}
+
+// received blocks are relayed to the rest of the network and recorded as pending uncles of the coalition.
+// the coalition's chain never switches on its own, that is up to the strategy.
+func (s *SelfishMiner) ReceiveBlock(b *Block) {
+	if !s.RecordBlock(b) {
+		return
+	}
+	s.EnqueueBlock(b)
+	s.coalition.received(b)
+}
+
+func (s *SelfishMiner) RecordBlock(b *Block) bool {
+	return s.coalition.chain.RecordBlock(b)
+}
+
+func (s *SelfishMiner) SwitchChain(b *Block) {
+	s.coalition.chain.SwitchChain(b)
+}
+
+func (s *SelfishMiner) GetBlockchain() []*Block {
+	return s.coalition.chain.GetBlockchain()
+}
+
+func (s *SelfishMiner) GetLastBlock() *Block {
+	return s.coalition.chain.GetLastBlock()
+}
+
+func (s *SelfishMiner) AppendBlock(b *Block) {
+	s.coalition.chain.AppendBlock(b)
+}
+
+func (s *SelfishMiner) AddBlocks(b []*Block) {
+	s.coalition.chain.AddBlocks(b)
+}
+
+func (s *SelfishMiner) RemoveBlock(b *Block) {
+	s.coalition.chain.RemoveBlock(b)
+}
+
+func (s *SelfishMiner) RemoveBlocks(b []*Block) {
+	s.coalition.chain.RemoveBlocks(b)
+}
+
+func (s *SelfishMiner) AppendUncle(b *Block) {
+	s.coalition.chain.AppendUncle(b)
+}
+
+func (s *SelfishMiner) IncludeUncle(b *Block) {
+	s.coalition.chain.IncludeUncle(b)
+}
+
+func (s *SelfishMiner) IncludeUncles(b []*Block) {
+	s.coalition.chain.IncludeUncles(b)
+}
+
+func (s *SelfishMiner) GetPendingUncles() map[string]*Block {
+	return s.coalition.chain.GetPendingUncles()
+}
+
+func (s *SelfishMiner) CalculateGains(maxDepth int, uncleDivisor, nephewReward float64) map[string][]float64 {
+	return s.coalition.chain.CalculateGains(maxDepth, uncleDivisor, nephewReward)
+}
@@ -0,0 +1,370 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+const (
+	TICK_LENGTH     = 100
+	BLOCK_CHANCE    = 0.2
+	BLOCK_TIME      = TICK_LENGTH / BLOCK_CHANCE      //targeted time between blocks
+	BLOCK_REWARD    = TICK_LENGTH / BLOCK_CHANCE * 10 //google says fees are typically 10% of eth block rewards; we give fees = time since last block
+	FEES_PER_SECOND = 1
+)
+
+// Result holds the outcome of a single simulation run.
+type Result struct {
+	Run        int
+	Time       int //length of the run
+	Miners     []Miner
+	Gains      map[string][]float64 //minerID -> rewards gained, main blocks created, uncle blocks created
+	Coalitions map[string][]string  //coalition id -> ids of the colluding selfish miners
+	Neighbors  map[string]int       //minerID -> number of neighbors at the start of the run
+	Eclipsed   map[string][]string  //adversary id -> ids of the miners it eclipses
+	Observer   *Observer            //block tree of the run and its canonical chain
+}
+
+// gains of the members of each coalition of more than one miner, added up under the coalition id.
+func (r *Result) CoalitionGains() map[string][]float64 {
+	pooled := make(map[string][]float64)
+	for id, members := range r.Coalitions {
+		if len(members) < 2 {
+			continue
+		}
+		pooled[id] = []float64{0, 0, 0}
+		for _, m := range members {
+			for i, v := range r.Gains[m] {
+				pooled[id][i] += v
+			}
+		}
+	}
+	return pooled
+}
+
+// Simulate performs a single run of the simulation described by conf.
+// runs are consistently seeded, so the same seed and run number always yield the same result.
+// every run has its own source of randomness, so runs can be performed in parallel.
+func Simulate(conf Config, run int) (*Result, error) {
+	seed := conf.Seed
+	if seed == 0 {
+		seed = DEFAULT_SEED
+	}
+	rng := rand.New(rand.NewSource(seed + int64(run)))
+	//every miner gets its own fork choice and tie break, which keep track of the blocks known to it.
+	if _, err := NewForkChoice(conf.ForkChoice); err != nil {
+		return nil, err
+	}
+	if _, err := NewTieBreak(conf.TieBreak, conf.Gamma, rng); err != nil {
+		return nil, err
+	}
+	forkChoice := func() ForkChoice {
+		f, _ := NewForkChoice(conf.ForkChoice)
+		return f
+	}
+	tieBreak := func() TieBreak {
+		t, _ := NewTieBreak(conf.TieBreak, conf.Gamma, rng)
+		return t
+	}
+	observer := NewObserver(forkChoice())
+	totalMiningPower := 0
+	miners := []Miner{}
+	numMiners := conf.Miners
+
+	//each adversary replaces an honest miner, which one is specified by the adversary's power param.
+	//adversaries with the same power replace consecutive miners.
+	adversaries, err := conf.GetAdversaries()
+	if err != nil {
+		return nil, err
+	}
+	if len(adversaries) > numMiners {
+		return nil, fmt.Errorf("%d adversaries but only %d miners", len(adversaries), numMiners)
+	}
+	slots := make(map[int]Adversary)
+	coalitions := make(map[string]*Coalition)
+	for _, a := range adversaries {
+		slot := int(math.Floor(float64(numMiners) * a.Power))
+		if slot < 0 {
+			slot = 0
+		}
+		for {
+			if _, taken := slots[slot%numMiners]; !taken {
+				break
+			}
+			slot++
+		}
+		slots[slot%numMiners] = a
+		if _, found := coalitions[a.Coalition]; !found {
+			strategy, err := NewStrategy(a.Strategy, a.Delay, a.TrailDepth)
+			if err != nil {
+				return nil, fmt.Errorf("adversary %s: %v", a.ID, err)
+			}
+			unclePolicy, err := NewUnclePolicy(a.UnclePolicy)
+			if err != nil {
+				return nil, fmt.Errorf("adversary %s: %v", a.ID, err)
+			}
+			coalitions[a.Coalition] = NewCoalition(a.Coalition, strategy, unclePolicy)
+		}
+	}
+
+	//create a number of miners and add to miners slice.
+	//number and power distribution of miners specified in config.
+	ids := make(map[string]bool)
+	for i := 0; i < numMiners; i++ {
+		newMinerPowa := int(math.Floor(math.Pow(conf.PowerScaling, float64(i))))
+		if a, found := slots[i]; found {
+			miners = append(miners, NewSelfishMiner(a.ID, nil, newMinerPowa, coalitions[a.Coalition]))
+		} else {
+			miners = append(miners, NewMiner(fmt.Sprintf("m%d", i), nil, newMinerPowa, conf.MaxUncles))
+		}
+		miners[i].SetForkChoice(forkChoice())
+		miners[i].SetTieBreak(tieBreak())
+		miners[i].SetRand(rng)
+		ids[miners[i].GetID()] = true
+
+		totalMiningPower += newMinerPowa
+	}
+	members := make(map[string][]string)
+	for id, c := range coalitions {
+		for _, m := range c.GetMembers() {
+			members[id] = append(members[id], m.GetID())
+		}
+		//pooled gains are reported under the coalition id.
+		if len(members[id]) > 1 && ids[id] {
+			return nil, fmt.Errorf("coalition id %q is also the id of a miner", id)
+		}
+	}
+
+	//miners whose first scheduled event is joining start offline.
+	schedule, err := sortedSchedule(conf.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	offline := make(map[string]bool)
+	for _, e := range schedule {
+		if _, found := offline[e.Miner]; !found {
+			offline[e.Miner] = e.Action == POWER_JOIN && ids[e.Miner]
+		}
+	}
+	totalMiningPower = onlineMiningPower(miners, offline)
+	if totalMiningPower == 0 {
+		return nil, fmt.Errorf("no mining power online at the start of the run")
+	}
+
+	//the initial difficulty makes the initial mining power find a block every BLOCK_TIME.
+	interval := conf.RetargetInterval
+	if interval == 0 {
+		interval = 2016
+	}
+	retarget, err := NewRetarget(conf.Retarget, InitialDifficulty(totalMiningPower), interval)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range miners {
+		i.SetRetarget(retarget)
+	}
+
+	latency, err := NewLatency(conf.Latency, conf.LatencyMean, conf.LatencyFile, rng)
+	if err != nil {
+		return nil, err
+	}
+	var links map[string]map[string]float64
+	if conf.BandwidthFile != "" {
+		if links, err = ReadBandwidthMatrix(conf.BandwidthFile); err != nil {
+			return nil, err
+		}
+	}
+	network := NewNetwork(latency, conf.Bandwidth, links, conf.BlockSize)
+	network.SetObserver(observer)
+	for _, i := range miners {
+		i.SetNetwork(network)
+	}
+
+	//set neighbors for each miner
+	degree := conf.Degree
+	if degree == 0 {
+		degree = 5
+	}
+	topology, err := NewTopology(conf.Topology, degree, conf.EdgeProbability, conf.Rewire, conf.TopologyFile, rng)
+	if err != nil {
+		return nil, err
+	}
+	if err := Connect(miners, topology); err != nil {
+		return nil, err
+	}
+	if err := positionAdversaries(miners, adversaries, rng); err != nil {
+		return nil, err
+	}
+	neighbors := make(map[string]int)
+	for _, i := range miners {
+		neighbors[i.GetID()] = len(i.GetNeighbors())
+	}
+	eclipsed := make(map[string][]string)
+	for _, a := range adversaries {
+		eclipsed[a.ID] = a.Eclipse
+	}
+
+	state := &simulation{
+		conf:       conf,
+		miners:     miners,
+		offline:    offline,
+		schedule:   schedule,
+		observer:   observer,
+		network:    network,
+		coalitions: coalitions,
+		power:      totalMiningPower,
+		rng:        rng,
+		degree:     degree,
+		newMiner: func(id string, power int) Miner {
+			m := NewMiner(id, nil, power, conf.MaxUncles)
+			m.SetForkChoice(forkChoice())
+			m.SetTieBreak(tieBreak())
+			m.SetRand(rng)
+			m.SetRetarget(retarget)
+			m.SetNetwork(network)
+			return m
+		},
+	}
+	if err := state.runTicks(); err != nil {
+		return nil, err
+	}
+	miners = state.miners
+
+	//calculate mining rewards
+	gains := observer.CalculateGains(conf.MaxDepth, conf.UncleDivisor, conf.NephewReward)
+	return &Result{Run: run, Time: conf.Time, Miners: miners, Gains: gains, Coalitions: members, Neighbors: neighbors, Eclipsed: eclipsed, Observer: observer}, nil
+}
+
+// SimulateRuns performs conf.Runs runs of the simulation on up to workers goroutines at a time.
+// results are returned in run order and do not depend on the number of workers.
+func SimulateRuns(conf Config, workers int) ([]*Result, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]*Result, conf.Runs)
+	errs := make([]error, conf.Runs)
+	runs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for run := range runs {
+				results[run], errs[run] = Simulate(conf, run)
+			}
+		}()
+	}
+	for run := 0; run < conf.Runs; run++ {
+		runs <- run
+	}
+	close(runs)
+	wg.Wait()
+	for run, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %v", run, err)
+		}
+	}
+	return results, nil
+}
+
+// state of a run
+type simulation struct {
+	conf       Config
+	miners     []Miner
+	offline    map[string]bool //miners that left the network neither mine nor communicate
+	schedule   []PowerEvent    //events not applied yet, sorted by time
+	observer   *Observer
+	network    *Network
+	coalitions map[string]*Coalition
+	power      int //mining power of the miners online
+	degree     int //links of a miner joining the network
+	rng        *rand.Rand
+	newMiner   func(id string, power int) Miner //creates a miner joining the network
+}
+
+// applies the scheduled events due by time and returns the miners they affected.
+func (s *simulation) applySchedule(time int) ([]Miner, error) {
+	affected := []Miner{}
+	for len(s.schedule) > 0 && s.schedule[0].Time <= time {
+		m, err := s.apply(s.schedule[0])
+		if err != nil {
+			return nil, err
+		}
+		affected = append(affected, m)
+		s.schedule = s.schedule[1:]
+	}
+	if len(affected) > 0 {
+		s.power = onlineMiningPower(s.miners, s.offline)
+	}
+	return affected, nil
+}
+
+// applies a scheduled event; a miner (re)joining first catches up with the canonical chain.
+func (s *simulation) apply(e PowerEvent) (Miner, error) {
+	var miner Miner
+	for _, i := range s.miners {
+		if i.GetID() == e.Miner {
+			miner = i
+		}
+	}
+	if miner == nil {
+		if e.Action != POWER_JOIN {
+			return nil, fmt.Errorf("power event at %d: unknown miner %q", e.Time, e.Miner)
+		}
+		if e.Power == 0 {
+			return nil, fmt.Errorf("power event at %d: new miner %q needs mining power", e.Time, e.Miner)
+		}
+		miner = s.newMiner(e.Miner, e.Power)
+		miner.GenerateNeighbors(s.miners, s.degree, true)
+		s.miners = append(s.miners, miner)
+		s.offline[e.Miner] = true
+	}
+	switch e.Action {
+	case POWER_JOIN:
+		if e.Power > 0 {
+			miner.SetMiningPower(e.Power)
+		}
+		if s.offline[e.Miner] {
+			miner.ReceiveBlock(s.observer.GetLastBlock())
+		}
+		s.offline[e.Miner] = false
+	case POWER_LEAVE:
+		s.offline[e.Miner] = true
+	case POWER_CHANGE:
+		miner.SetMiningPower(e.Power)
+	}
+	return miner, nil
+}
+
+// for each time step, execute the subfunctions of a Tick for each online miner
+func (s *simulation) runTicks() error {
+	time := 0
+	for time < s.conf.Time {
+		time += TICK_LENGTH
+		s.network.SetTime(time)
+		if _, err := s.applySchedule(time); err != nil {
+			return err
+		}
+		for _, i := range s.miners {
+			if !s.offline[i.GetID()] {
+				i.TickMine(s.power, time, s.conf.MaxDepth, s.conf.MaxUncles)
+			}
+		}
+		for _, i := range s.miners {
+			if !s.offline[i.GetID()] {
+				i.TickCommunicate()
+			}
+		}
+		s.network.Deliver()
+		for _, i := range s.miners {
+			if s.offline[i.GetID()] {
+				i.ClearReadQueue()
+			} else {
+				i.TickRead()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package sim
+
+import (
+	"fmt"
+)
+
+const (
+	STRATEGY_DELAY               = "delay"
+	STRATEGY_SM1                 = "sm1"
+	STRATEGY_LEAD_STUBBORN       = "lead-stubborn"
+	STRATEGY_EQUAL_FORK_STUBBORN = "equal-fork-stubborn"
+	STRATEGY_TRAIL_STUBBORN      = "trail-stubborn"
+)
+
+// events a selfish miner consults its strategy on
+const (
+	EVENT_MINED  = iota //the selfish miner extended its private chain
+	EVENT_PUBLIC        //the honest network extended the public chain
+	EVENT_TICK          //a communication step passed
+)
+
+// StrategyView is the selfish miner's state as seen by its strategy.
+type StrategyView struct {
+	Event      int
+	Block      *Block   //block mined or received, nil on EVENT_TICK
+	PrivateTip *Block   //last block of the chain the selfish miner mines on
+	PublicTip  *Block   //deepest block known to the honest network
+	Withheld   []*Block //mined but unpublished blocks on the private chain, in order of depth
+}
+
+// lead of the private chain over the public chain
+func (v *StrategyView) Lead() int {
+	return v.PrivateTip.depth - v.PublicTip.depth
+}
+
+// withheld blocks up to and including the given depth
+func (v *StrategyView) WithheldUpTo(depth int) []*Block {
+	blocks := []*Block{}
+	for _, b := range v.Withheld {
+		if b.depth <= depth {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// true if the selfish miner just mined on a published block of its own that is tied with
+// the public tip, i.e. it was racing the honest network (state 0')
+func (v *StrategyView) Racing() bool {
+	if v.Event != EVENT_MINED {
+		return false
+	}
+	parent := v.Block.parent
+	for _, b := range v.Withheld {
+		if b.Equals(parent) {
+			return false
+		}
+	}
+	return parent.depth == v.PublicTip.depth && !parent.Equals(v.PublicTip)
+}
+
+// Strategy decides what a selfish miner publishes and which tip it mines on.
+// both are consulted after every event; see SelfishMiner.
+type Strategy interface {
+	GetName() string
+	//blocks to publish in response to the event in v
+	Publish(v *StrategyView) []*Block
+	//block to mine on after the event in v, either v.PrivateTip or v.PublicTip
+	MiningTip(v *StrategyView) *Block
+}
+
+// creates the strategy with the given name.
+// selfishDelay is only used by the delay strategy, trailDepth only by the trail-stubborn strategy.
+func NewStrategy(name string, selfishDelay, trailDepth int) (Strategy, error) {
+	switch name {
+	case "", STRATEGY_DELAY:
+		if selfishDelay < 1 {
+			return nil, fmt.Errorf("strategy %s: selfish delay must be at least 1, got %d", STRATEGY_DELAY, selfishDelay)
+		}
+		return &delayStrategy{delay: selfishDelay}, nil
+	case STRATEGY_SM1:
+		return &stubbornStrategy{name: name}, nil
+	case STRATEGY_LEAD_STUBBORN:
+		return &stubbornStrategy{name: name, leadStubborn: true}, nil
+	case STRATEGY_EQUAL_FORK_STUBBORN:
+		return &stubbornStrategy{name: name, equalForkStubborn: true}, nil
+	case STRATEGY_TRAIL_STUBBORN:
+		if trailDepth < 1 {
+			return nil, fmt.Errorf("strategy %s: trail depth must be at least 1, got %d", name, trailDepth)
+		}
+		return &stubbornStrategy{name: name, trailDepth: trailDepth}, nil
+	}
+	return nil, fmt.Errorf("unknown selfish strategy %q", name)
+}
+
+// publishes every block a fixed number of communication steps after it was found,
+// regardless of the state of the public chain.
+// mines on the deepest known block.
+type delayStrategy struct {
+	delay   int
+	pending []*Block
+	ticks   []int //communication steps left for each pending block
+}
+
+func (d *delayStrategy) GetName() string {
+	return STRATEGY_DELAY
+}
+
+func (d *delayStrategy) Publish(v *StrategyView) []*Block {
+	switch v.Event {
+	case EVENT_MINED:
+		d.pending = append(d.pending, v.Block)
+		d.ticks = append(d.ticks, d.delay)
+	case EVENT_TICK:
+		publish := []*Block{}
+		pending := []*Block{}
+		ticks := []int{}
+		for i, b := range d.pending {
+			if d.ticks[i] <= 1 {
+				publish = append(publish, b)
+				continue
+			}
+			pending = append(pending, b)
+			ticks = append(ticks, d.ticks[i]-1)
+		}
+		d.pending, d.ticks = pending, ticks
+		return publish
+	}
+	return nil
+}
+
+func (d *delayStrategy) MiningTip(v *StrategyView) *Block {
+	if v.Lead() < 0 {
+		return v.PublicTip
+	}
+	return v.PrivateTip
+}
+
+// Eyal-Sirer selfish mining (SM1), optionally with the stubborn variants of Nayak et al.:
+//
+//	lead-stubborn: when the honest network catches up, only match its depth, never override
+//	equal-fork-stubborn: when winning a race, keep the new block private instead of publishing
+//	trail-stubborn: keep mining on the private chain until trailing by more than trailDepth blocks
+type stubbornStrategy struct {
+	name              string
+	leadStubborn      bool
+	equalForkStubborn bool
+	trailDepth        int
+}
+
+func (s *stubbornStrategy) GetName() string {
+	return s.name
+}
+
+func (s *stubbornStrategy) Publish(v *StrategyView) []*Block {
+	lead := v.Lead()
+	switch v.Event {
+	case EVENT_MINED:
+		//caught up with the public chain from behind: match it.
+		if lead == 0 {
+			return v.Withheld
+		}
+		//found a block while racing: publish to win the race.
+		if v.Racing() && !s.equalForkStubborn {
+			return v.WithheldUpTo(v.PrivateTip.depth)
+		}
+	case EVENT_PUBLIC:
+		switch {
+		case lead < 0:
+			return nil
+		case lead == 0:
+			//race the honest block (state 0').
+			return v.Withheld
+		case lead == 1 && !s.leadStubborn:
+			//override the public chain.
+			return v.Withheld
+		default:
+			//match the public chain, keeping the lead.
+			return v.WithheldUpTo(v.PublicTip.depth)
+		}
+	}
+	return nil
+}
+
+func (s *stubbornStrategy) MiningTip(v *StrategyView) *Block {
+	if -v.Lead() > s.trailDepth {
+		return v.PublicTip
+	}
+	return v.PrivateTip
+}
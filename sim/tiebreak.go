@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	TIE_FIRST_SEEN = "first-seen"
+	TIE_RANDOM     = "random"
+	TIE_TIMESTAMP  = "timestamp"
+	TIE_OWN        = "own"
+)
+
+// TieBreak decides between two chains the fork choice rule considers equally good.
+// a tie break may keep track of earlier ties, so every miner needs its own.
+type TieBreak interface {
+	GetName() string
+	//true if the miner should switch from the chain ending in current to the tied chain ending in candidate
+	Switch(minerID string, current, candidate *Block) bool
+}
+
+// creates a tie break policy by name:
+//
+//	first-seen (default): keep the current block, except that a selfish block beats an honest one with probability gamma
+//	random: every tied block is equally likely to be mined on
+//	timestamp: the block with the lowest timestamp
+//	own: the miner's own block, otherwise a coin flip
+func NewTieBreak(name string, gamma float64, rng *rand.Rand) (TieBreak, error) {
+	switch name {
+	case "", TIE_FIRST_SEEN:
+		return &firstSeen{gamma: gamma, rng: rng}, nil
+	case TIE_RANDOM:
+		return &randomTie{rng: rng}, nil
+	case TIE_TIMESTAMP:
+		return &lowestTimestamp{}, nil
+	case TIE_OWN:
+		return &preferOwn{rng: rng}, nil
+	}
+	return nil, fmt.Errorf("unknown tie break policy %q", name)
+}
+
+type firstSeen struct {
+	gamma float64 //probability of switching to a selfish block tied with an honest one
+	rng   *rand.Rand
+}
+
+func (f *firstSeen) GetName() string {
+	return TIE_FIRST_SEEN
+}
+
+func (f *firstSeen) Switch(minerID string, current, candidate *Block) bool {
+	return candidate.selfish && !current.selfish && f.rng.Float64() < f.gamma
+}
+
+// uniform over all blocks tied with the current one:
+// the n-th tied block seen replaces the current block with probability 1/n.
+type randomTie struct {
+	tip  *Block //block chosen among the current ties
+	seen int    //blocks seen in the current ties
+	rng  *rand.Rand
+}
+
+func (r *randomTie) GetName() string {
+	return TIE_RANDOM
+}
+
+func (r *randomTie) Switch(minerID string, current, candidate *Block) bool {
+	if r.tip == nil || !r.tip.Equals(current) {
+		r.tip, r.seen = current, 1
+	}
+	r.seen++
+	if r.rng.Intn(r.seen) == 0 {
+		r.tip = candidate
+		return true
+	}
+	return false
+}
+
+type lowestTimestamp struct{}
+
+func (l *lowestTimestamp) GetName() string {
+	return TIE_TIMESTAMP
+}
+
+func (l *lowestTimestamp) Switch(minerID string, current, candidate *Block) bool {
+	return candidate.timestamp < current.timestamp
+}
+
+type preferOwn struct {
+	rng *rand.Rand
+}
+
+func (p *preferOwn) GetName() string {
+	return TIE_OWN
+}
+
+func (p *preferOwn) Switch(minerID string, current, candidate *Block) bool {
+	switch {
+	case current.minerID == minerID:
+		return false
+	case candidate.minerID == minerID:
+		return true
+	}
+	return p.rng.Float64() < 0.5
+}
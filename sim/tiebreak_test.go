@@ -0,0 +1,66 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTieBreak(t *testing.T) {
+	genesis := NewBlock("genesis", nil, nil, 0)
+	//two blocks at the same depth, the selfish one found first.
+	selfish := NewBlock("s", genesis, nil, 10)
+	selfish.selfish = true
+	honest := NewBlock("h", genesis, nil, 20)
+
+	tests := []struct {
+		policy             string
+		gamma              float64
+		minerID            string
+		current, candidate *Block
+		want               bool
+	}{
+		{TIE_FIRST_SEEN, 0, "m", honest, selfish, false},
+		{TIE_FIRST_SEEN, 1, "m", honest, selfish, true},
+		{TIE_FIRST_SEEN, 1, "m", selfish, honest, false}, //gamma only favors selfish blocks
+		{TIE_TIMESTAMP, 0, "m", honest, selfish, true},
+		{TIE_TIMESTAMP, 0, "m", selfish, honest, false},
+		{TIE_OWN, 0, "h", honest, selfish, false},
+		{TIE_OWN, 0, "h", selfish, honest, true},
+	}
+	for _, tt := range tests {
+		tie, err := NewTieBreak(tt.policy, tt.gamma, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tie.Switch(tt.minerID, tt.current, tt.candidate); got != tt.want {
+			t.Errorf("%s with gamma %v: miner %s switches from %s to %s: %v, want %v",
+				tt.policy, tt.gamma, tt.minerID, tt.current.GetID(), tt.candidate.GetID(), got, tt.want)
+		}
+	}
+}
+
+// random, first-seen with gamma 0.5, and own when neither block is the miner's, switch on about half of the ties.
+func TestTieBreakFrequency(t *testing.T) {
+	genesis := NewBlock("genesis", nil, nil, 0)
+	const ties = 10000
+	for _, policy := range []string{TIE_RANDOM, TIE_FIRST_SEEN, TIE_OWN} {
+		tie, err := NewTieBreak(policy, 0.5, rand.New(rand.NewSource(1230)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		switches := 0
+		for i := 0; i < ties; i++ {
+			//a new tie every time, so random does not count earlier ties.
+			current := NewBlock("h", genesis, nil, 2*i+1)
+			candidate := NewBlock("s", genesis, nil, 2*i+2)
+			candidate.selfish = true
+			if tie.Switch("m", current, candidate) {
+				switches++
+			}
+		}
+		if share := float64(switches) / ties; math.Abs(share-0.5) > 0.02 {
+			t.Errorf("%s switches on %.3f of the ties, want 0.5", policy, share)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	TOPOLOGY_RANDOM          = "random"
+	TOPOLOGY_RANDOM_REGULAR  = "random-regular"
+	TOPOLOGY_ERDOS_RENYI     = "erdos-renyi"
+	TOPOLOGY_WATTS_STROGATZ  = "watts-strogatz"
+	TOPOLOGY_BARABASI_ALBERT = "barabasi-albert"
+	TOPOLOGY_FULL            = "full"
+	TOPOLOGY_FILE            = "file"
+)
+
+// Topology decides which miners are neighbors.
+// links are undirected: both miners of a link send blocks to each other.
+type Topology interface {
+	GetName() string
+	//links between the miners with the given ids, as pairs of indices into ids
+	Links(ids []string) ([][2]int, error)
+}
+
+// creates a network topology by name:
+//
+//	random (default): every miner links to degree other miners picked at random
+//	random-regular: every miner has exactly degree links
+//	erdos-renyi: every pair of miners is linked with probability p, default degree/(miners-1)
+//	watts-strogatz: a ring in which every miner links to its degree nearest miners, each link rewired with probability rewire
+//	barabasi-albert: miners are added one by one, each linking to degree earlier miners picked proportional to their links
+//	full: every pair of miners is linked
+//	file: links are read from an edge list file, one pair of miner ids per line
+//
+// generated topologies are made connected by linking their components, a topology read from file must be connected already.
+func NewTopology(name string, degree int, p, rewire float64, file string, rng *rand.Rand) (Topology, error) {
+	if degree == 0 {
+		degree = 5
+	}
+	if degree < 0 {
+		return nil, fmt.Errorf("topology %s: negative degree %d", name, degree)
+	}
+	if p < 0 || p > 1 || rewire < 0 || rewire > 1 {
+		return nil, fmt.Errorf("topology %s: probabilities must be in [0,1]", name)
+	}
+	switch name {
+	case "", TOPOLOGY_RANDOM:
+		return &randomTopology{degree: degree, rng: rng}, nil
+	case TOPOLOGY_RANDOM_REGULAR:
+		return &randomRegular{degree: degree, rng: rng}, nil
+	case TOPOLOGY_ERDOS_RENYI:
+		return &erdosRenyi{degree: degree, p: p, rng: rng}, nil
+	case TOPOLOGY_WATTS_STROGATZ:
+		return &wattsStrogatz{degree: degree, rewire: rewire, rng: rng}, nil
+	case TOPOLOGY_BARABASI_ALBERT:
+		return &barabasiAlbert{degree: degree, rng: rng}, nil
+	case TOPOLOGY_FULL:
+		return &fullMesh{}, nil
+	case TOPOLOGY_FILE:
+		return &edgeList{file: file}, nil
+	}
+	return nil, fmt.Errorf("unknown topology %q", name)
+}
+
+// makes the miners neighbors as laid out by the topology.
+// fails on self-loops and on a graph that is not connected; links listed twice are added once.
+func Connect(miners []Miner, t Topology) error {
+	ids := make([]string, len(miners))
+	for i, m := range miners {
+		ids[i] = m.GetID()
+	}
+	links, err := t.Links(ids)
+	if err != nil {
+		return err
+	}
+	g := newGraph(len(miners))
+	for _, l := range links {
+		if l[0] == l[1] {
+			return fmt.Errorf("topology %s: %s links to itself", t.GetName(), ids[l[0]])
+		}
+		g.link(l[0], l[1])
+	}
+	if len(g.components()) > 1 {
+		return fmt.Errorf("topology %s: miners are not connected", t.GetName())
+	}
+	for _, l := range g.links() {
+		miners[l[0]].AddNeighbor(miners[l[1]], true)
+	}
+	return nil
+}
+
+// undirected graph without duplicate links
+type graph struct {
+	adjacent []map[int]bool
+}
+
+func newGraph(n int) *graph {
+	g := &graph{adjacent: make([]map[int]bool, n)}
+	for i := range g.adjacent {
+		g.adjacent[i] = make(map[int]bool)
+	}
+	return g
+}
+
+// returns false for self-loops and links already present
+func (g *graph) link(a, b int) bool {
+	if a == b || g.adjacent[a][b] {
+		return false
+	}
+	g.adjacent[a][b] = true
+	g.adjacent[b][a] = true
+	return true
+}
+
+func (g *graph) unlink(a, b int) {
+	delete(g.adjacent[a], b)
+	delete(g.adjacent[b], a)
+}
+
+// links in a deterministic order, each once with the lower index first
+func (g *graph) links() [][2]int {
+	links := [][2]int{}
+	for a := range g.adjacent {
+		for b := range g.adjacent[a] {
+			if a < b {
+				links = append(links, [2]int{a, b})
+			}
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i][0] != links[j][0] {
+			return links[i][0] < links[j][0]
+		}
+		return links[i][1] < links[j][1]
+	})
+	return links
+}
+
+// connected components, each listed by increasing index
+func (g *graph) components() [][]int {
+	component := make([]int, len(g.adjacent))
+	for i := range component {
+		component[i] = -1
+	}
+	components := [][]int{}
+	for start := range g.adjacent {
+		if component[start] >= 0 {
+			continue
+		}
+		c := len(components)
+		members := []int{}
+		stack := []int{start}
+		component[start] = c
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			members = append(members, n)
+			for m := range g.adjacent[n] {
+				if component[m] < 0 {
+					component[m] = c
+					stack = append(stack, m)
+				}
+			}
+		}
+		sort.Ints(members)
+		components = append(components, members)
+	}
+	return components
+}
+
+// links a random miner of every component to a random miner of the previous one, then returns the links.
+func (g *graph) connected(rng *rand.Rand) [][2]int {
+	components := g.components()
+	for i := 1; i < len(components); i++ {
+		a, b := components[i-1], components[i]
+		g.link(a[rng.Intn(len(a))], b[rng.Intn(len(b))])
+	}
+	return g.links()
+}
+
+type randomTopology struct {
+	degree int
+	rng    *rand.Rand
+}
+
+func (r *randomTopology) GetName() string {
+	return TOPOLOGY_RANDOM
+}
+
+func (r *randomTopology) Links(ids []string) ([][2]int, error) {
+	n := len(ids)
+	g := newGraph(n)
+	for a := 0; a < n; a++ {
+		for _, b := range pickOthers(r.rng, n, a, r.degree) {
+			g.link(a, b)
+		}
+	}
+	return g.connected(r.rng), nil
+}
+
+type randomRegular struct {
+	degree int
+	rng    *rand.Rand
+}
+
+func (r *randomRegular) GetName() string {
+	return TOPOLOGY_RANDOM_REGULAR
+}
+
+// pairs up free link ends at random, starting over when only self-loops or duplicates are left.
+func (r *randomRegular) Links(ids []string) ([][2]int, error) {
+	n := len(ids)
+	if r.degree >= n || n*r.degree%2 != 0 {
+		return nil, fmt.Errorf("topology %s: no graph of %d miners with degree %d", TOPOLOGY_RANDOM_REGULAR, n, r.degree)
+	}
+	for attempt := 0; attempt < 1000; attempt++ {
+		g := newGraph(n)
+		stubs := []int{}
+		for i := 0; i < n; i++ {
+			for j := 0; j < r.degree; j++ {
+				stubs = append(stubs, i)
+			}
+		}
+		for len(stubs) > 0 {
+			paired := false
+			for try := 0; try < 100 && !paired; try++ {
+				i, j := r.rng.Intn(len(stubs)), r.rng.Intn(len(stubs))
+				if i != j && g.link(stubs[i], stubs[j]) {
+					if i < j {
+						i, j = j, i
+					}
+					stubs = append(stubs[:i], stubs[i+1:]...)
+					stubs = append(stubs[:j], stubs[j+1:]...)
+					paired = true
+				}
+			}
+			if !paired {
+				break
+			}
+		}
+		if len(stubs) == 0 && len(g.components()) == 1 {
+			return g.links(), nil
+		}
+	}
+	return nil, fmt.Errorf("topology %s: no connected graph of %d miners with degree %d found", TOPOLOGY_RANDOM_REGULAR, n, r.degree)
+}
+
+type erdosRenyi struct {
+	degree int
+	p      float64
+	rng    *rand.Rand
+}
+
+func (e *erdosRenyi) GetName() string {
+	return TOPOLOGY_ERDOS_RENYI
+}
+
+func (e *erdosRenyi) Links(ids []string) ([][2]int, error) {
+	n := len(ids)
+	p := e.p
+	if p == 0 && n > 1 {
+		p = float64(e.degree) / float64(n-1)
+	}
+	g := newGraph(n)
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			if e.rng.Float64() < p {
+				g.link(a, b)
+			}
+		}
+	}
+	return g.connected(e.rng), nil
+}
+
+type wattsStrogatz struct {
+	degree int
+	rewire float64
+	rng    *rand.Rand
+}
+
+func (w *wattsStrogatz) GetName() string {
+	return TOPOLOGY_WATTS_STROGATZ
+}
+
+// every miner links to the degree/2 miners after it on the ring, then each link is moved to a random miner with probability rewire.
+func (w *wattsStrogatz) Links(ids []string) ([][2]int, error) {
+	n := len(ids)
+	if w.degree >= n {
+		return nil, fmt.Errorf("topology %s: degree %d needs more than %d miners", TOPOLOGY_WATTS_STROGATZ, w.degree, n)
+	}
+	g := newGraph(n)
+	for a := 0; a < n; a++ {
+		for k := 1; k <= w.degree/2; k++ {
+			g.link(a, (a+k)%n)
+		}
+	}
+	for k := 1; k <= w.degree/2; k++ {
+		for a := 0; a < n; a++ {
+			b := (a + k) % n
+			if !g.adjacent[a][b] || w.rng.Float64() >= w.rewire || len(g.adjacent[a]) >= n-1 {
+				continue
+			}
+			c := w.rng.Intn(n)
+			for c == a || g.adjacent[a][c] {
+				c = w.rng.Intn(n)
+			}
+			g.unlink(a, b)
+			g.link(a, c)
+		}
+	}
+	return g.connected(w.rng), nil
+}
+
+type barabasiAlbert struct {
+	degree int
+	rng    *rand.Rand
+}
+
+func (b *barabasiAlbert) GetName() string {
+	return TOPOLOGY_BARABASI_ALBERT
+}
+
+// starts with a full mesh of degree+1 miners; every later miner links to degree distinct earlier ones,
+// each picked with probability proportional to its number of links.
+func (b *barabasiAlbert) Links(ids []string) ([][2]int, error) {
+	n := len(ids)
+	g := newGraph(n)
+	ends := []int{} //every miner once per link it has
+	for i := 0; i < n; i++ {
+		if i <= b.degree {
+			for j := 0; j < i; j++ {
+				g.link(i, j)
+				ends = append(ends, i, j)
+			}
+			continue
+		}
+		targets := make(map[int]bool)
+		for len(targets) < b.degree {
+			targets[ends[b.rng.Intn(len(ends))]] = true
+		}
+		for j := 0; j < i; j++ {
+			if targets[j] {
+				g.link(i, j)
+				ends = append(ends, i, j)
+			}
+		}
+	}
+	return g.connected(b.rng), nil
+}
+
+type fullMesh struct{}
+
+func (f *fullMesh) GetName() string {
+	return TOPOLOGY_FULL
+}
+
+func (f *fullMesh) Links(ids []string) ([][2]int, error) {
+	links := [][2]int{}
+	for a := range ids {
+		for b := a + 1; b < len(ids); b++ {
+			links = append(links, [2]int{a, b})
+		}
+	}
+	return links, nil
+}
+
+type edgeList struct {
+	file string
+}
+
+func (e *edgeList) GetName() string {
+	return TOPOLOGY_FILE
+}
+
+// lines hold two miner ids separated by whitespace or a comma; empty lines and lines starting with # are skipped.
+func (e *edgeList) Links(ids []string) ([][2]int, error) {
+	index := make(map[string]int)
+	for i, id := range ids {
+		index[id] = i
+	}
+	file, err := os.Open(e.file)
+	if err != nil {
+		return nil, fmt.Errorf("topology %s: %v", TOPOLOGY_FILE, err)
+	}
+	defer file.Close()
+	links := [][2]int{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(strings.ReplaceAll(text, ",", " "))
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected two miner ids, got %q", e.file, line, text)
+		}
+		a, foundA := index[fields[0]]
+		b, foundB := index[fields[1]]
+		if !foundA || !foundB {
+			return nil, fmt.Errorf("%s:%d: unknown miner in %q", e.file, line, text)
+		}
+		links = append(links, [2]int{a, b})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("topology %s: %v", TOPOLOGY_FILE, err)
+	}
+	return links, nil
+}
+
+// count distinct miners out of n picked at random, other than self
+func pickOthers(rng *rand.Rand, n, self, count int) []int {
+	if count > n-1 {
+		count = n - 1
+	}
+	picked := []int{}
+	for _, i := range rng.Perm(n) {
+		if len(picked) == count {
+			break
+		}
+		if i != self {
+			picked = append(picked, i)
+		}
+	}
+	return picked
+}
@@ -0,0 +1,161 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func minerIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("m%d", i)
+	}
+	return ids
+}
+
+// fails the test if links holds a self-loop or a link twice, in either direction, or does not connect all n miners.
+func checkLinks(t *testing.T, n int, links [][2]int) {
+	t.Helper()
+	g := newGraph(n)
+	for _, l := range links {
+		if l[0] == l[1] {
+			t.Fatalf("self-loop at %d", l[0])
+		}
+		if !g.link(l[0], l[1]) {
+			t.Fatalf("duplicate link %d-%d", l[0], l[1])
+		}
+	}
+	if c := len(g.components()); c != 1 {
+		t.Fatalf("%d components, want 1", c)
+	}
+}
+
+func TestGeneratedTopologies(t *testing.T) {
+	tests := []struct {
+		name   string
+		miners int
+		degree int
+		p      float64
+		rewire float64
+	}{
+		{TOPOLOGY_RANDOM, 50, 5, 0, 0},
+		{TOPOLOGY_RANDOM, 10, 1, 0, 0},
+		{TOPOLOGY_RANDOM, 3, 10, 0, 0},
+		{TOPOLOGY_RANDOM_REGULAR, 50, 4, 0, 0},
+		{TOPOLOGY_RANDOM_REGULAR, 10, 3, 0, 0},
+		{TOPOLOGY_ERDOS_RENYI, 50, 5, 0, 0},
+		{TOPOLOGY_ERDOS_RENYI, 50, 5, 0.01, 0}, //mostly isolated miners, joined up afterwards
+		{TOPOLOGY_WATTS_STROGATZ, 50, 4, 0, 0},
+		{TOPOLOGY_WATTS_STROGATZ, 50, 4, 0, 0.5},
+		{TOPOLOGY_WATTS_STROGATZ, 10, 8, 0, 1},
+		{TOPOLOGY_BARABASI_ALBERT, 50, 3, 0, 0},
+		{TOPOLOGY_BARABASI_ALBERT, 5, 5, 0, 0},
+		{TOPOLOGY_FULL, 20, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		for seed := int64(0); seed < 20; seed++ {
+			t.Run(fmt.Sprintf("%s/n%d/d%d/p%v/r%v/seed%d", tt.name, tt.miners, tt.degree, tt.p, tt.rewire, seed), func(t *testing.T) {
+				topology, err := NewTopology(tt.name, tt.degree, tt.p, tt.rewire, "", rand.New(rand.NewSource(seed)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				links, err := topology.Links(minerIDs(tt.miners))
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkLinks(t, tt.miners, links)
+				switch tt.name {
+				case TOPOLOGY_RANDOM_REGULAR:
+					degrees := make([]int, tt.miners)
+					for _, l := range links {
+						degrees[l[0]]++
+						degrees[l[1]]++
+					}
+					for i, d := range degrees {
+						if d != tt.degree {
+							t.Fatalf("miner %d has %d links, want %d", i, d, tt.degree)
+						}
+					}
+				case TOPOLOGY_FULL:
+					if want := tt.miners * (tt.miners - 1) / 2; len(links) != want {
+						t.Fatalf("%d links, want %d", len(links), want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestImpossibleTopologies(t *testing.T) {
+	tests := []struct {
+		name   string
+		miners int
+		degree int
+	}{
+		{TOPOLOGY_RANDOM_REGULAR, 5, 5}, //degree not below the number of miners
+		{TOPOLOGY_RANDOM_REGULAR, 5, 3}, //odd number of link ends
+		{TOPOLOGY_WATTS_STROGATZ, 4, 4},
+	}
+	for _, tt := range tests {
+		topology, err := NewTopology(tt.name, tt.degree, 0, 0, "", rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := topology.Links(minerIDs(tt.miners)); err == nil {
+			t.Errorf("%s with %d miners of degree %d: no error", tt.name, tt.miners, tt.degree)
+		}
+	}
+}
+
+func TestEdgeListTopology(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges string
+		fails bool
+	}{
+		{"path", "m0 m1\nm1,m2\n", false},
+		{"comments and duplicates", "# ring\n\nm0 m1\nm1 m0\nm1 m2\nm0,m1\nm2 m0\n", false},
+		{"self-loop", "m0 m1\nm1 m1\nm1 m2\n", true},
+		{"not connected", "m0 m1\n", true},
+		{"unknown miner", "m0 m1\nm1 m3\n", true},
+		{"three ids", "m0 m1 m2\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "edges.txt")
+			if err := os.WriteFile(path, []byte(tt.edges), 0644); err != nil {
+				t.Fatal(err)
+			}
+			topology, err := NewTopology(TOPOLOGY_FILE, 0, 0, 0, path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			miners := []Miner{}
+			for _, id := range minerIDs(3) {
+				miners = append(miners, NewMiner(id, nil, 1, 0))
+			}
+			err = Connect(miners, topology)
+			if tt.fails {
+				if err == nil {
+					t.Fatal("no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range miners {
+				seen := make(map[string]bool)
+				for _, n := range m.GetNeighbors() {
+					if n.GetID() == m.GetID() || seen[n.GetID()] {
+						t.Fatalf("%s: self-loop or duplicate neighbor %s", m.GetID(), n.GetID())
+					}
+					seen[n.GetID()] = true
+				}
+			}
+		})
+	}
+}
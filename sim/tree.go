@@ -0,0 +1,119 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	TREE_DOT  = "dot"
+	TREE_JSON = "json"
+)
+
+// TreeBlock is a block of a run's block tree, as exported to json.
+type TreeBlock struct {
+	ID        string
+	Parent    string   //empty for genesis
+	Uncles    []string //uncles included by the block, in order of id
+	Miner     string
+	Timestamp int
+	Fees      int
+	Main      bool //on the canonical chain
+	Selfish   bool
+}
+
+// blocks of the observer's tree with a timestamp in [from, to], in the order seen.
+// parents and uncles are named even if they fall outside the window.
+func (o *Observer) Tree(from, to int) []TreeBlock {
+	main := make(map[string]bool)
+	for _, b := range o.GetCanonicalChain() {
+		main[b.GetID()] = true
+	}
+	tree := []TreeBlock{}
+	for _, b := range o.Blocks() {
+		if b.timestamp < from || b.timestamp > to {
+			continue
+		}
+		t := TreeBlock{ID: b.GetID(), Uncles: []string{}, Miner: b.minerID, Timestamp: b.timestamp,
+			Fees: b.fees, Main: main[b.GetID()], Selfish: b.selfish}
+		if b.parent != nil {
+			t.Parent = b.parent.GetID()
+		}
+		for _, u := range b.sortedUncles() {
+			t.Uncles = append(t.Uncles, u.GetID())
+		}
+		tree = append(tree, t)
+	}
+	return tree
+}
+
+// writes the block tree of a run, limited to blocks with a timestamp in [from, to], in the given format:
+//
+//	dot: a graphviz digraph, see WriteTreeDOT
+//	json: a single document holding the run, the window and its blocks
+func WriteTree(w io.Writer, format string, r *Result, from, to int) error {
+	switch format {
+	case TREE_DOT:
+		return WriteTreeDOT(w, r, from, to)
+	case TREE_JSON:
+		return WriteTreeJSON(w, r, from, to)
+	}
+	return fmt.Errorf("unknown tree format %q", format)
+}
+
+// WriteTreeDOT writes the block tree as a graphviz digraph with edges from parent to child.
+// the canonical chain is drawn in bold blue, blocks of selfish miners are filled red,
+// and uncles point to the blocks including them with dashed edges.
+// edges to blocks outside the window are left out.
+func WriteTreeDOT(out io.Writer, r *Result, from, to int) error {
+	tree := r.Observer.Tree(from, to)
+	in := make(map[string]bool)
+	for _, t := range tree {
+		in[t.ID] = true
+	}
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "digraph run%d {\n", r.Run)
+	fmt.Fprintln(w, "\trankdir=LR;")
+	fmt.Fprintln(w, "\tnode [shape=box, style=filled, fillcolor=white];")
+	for _, t := range tree {
+		attrs := fmt.Sprintf("label=%q", fmt.Sprintf("%s\nt=%d fees=%d", t.Miner, t.Timestamp, t.Fees))
+		if t.Main {
+			attrs += ", color=blue, penwidth=3"
+		}
+		if t.Selfish {
+			attrs += ", fillcolor=salmon"
+		}
+		fmt.Fprintf(w, "\t%q [%s];\n", t.ID, attrs)
+	}
+	for _, t := range tree {
+		if in[t.Parent] {
+			attrs := ""
+			if t.Main {
+				attrs = " [color=blue, penwidth=3]"
+			}
+			fmt.Fprintf(w, "\t%q -> %q%s;\n", t.Parent, t.ID, attrs)
+		}
+		for _, u := range t.Uncles {
+			if in[u] {
+				fmt.Fprintf(w, "\t%q -> %q [style=dashed, constraint=false];\n", u, t.ID)
+			}
+		}
+	}
+	fmt.Fprintln(w, "}")
+	return w.Flush()
+}
+
+// WriteTreeJSON writes the block tree as a single json document with the run, the window and its blocks.
+func WriteTreeJSON(out io.Writer, r *Result, from, to int) error {
+	doc := struct {
+		Run    int
+		From   int
+		To     int
+		Blocks []TreeBlock
+	}{Run: r.Run, From: from, To: to, Blocks: r.Observer.Tree(from, to)}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	return enc.Encode(doc)
+}
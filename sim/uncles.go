@@ -0,0 +1,65 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	UNCLES_FRESHEST    = "freshest"
+	UNCLES_RECOUP      = "recoup"
+	UNCLES_RECOUP_DENY = "recoup-deny"
+)
+
+// UnclePolicy decides which pending uncles a miner references in a new block.
+type UnclePolicy struct {
+	Name       string
+	RecoupOwn  bool //keep own orphaned blocks as uncle candidates and reference them first
+	DenyOthers bool //never reference blocks of other miners, denying them the uncle reward
+}
+
+// creates the uncle policy with the given name:
+//
+//	freshest (default): reference the most recent uncles, which yield the highest uncle reward
+//	recoup: reference own orphaned blocks first to recoup their reward
+//	recoup-deny: reference only own orphaned blocks
+func NewUnclePolicy(name string) (*UnclePolicy, error) {
+	switch name {
+	case "", UNCLES_FRESHEST:
+		return &UnclePolicy{Name: UNCLES_FRESHEST}, nil
+	case UNCLES_RECOUP:
+		return &UnclePolicy{Name: name, RecoupOwn: true}, nil
+	case UNCLES_RECOUP_DENY:
+		return &UnclePolicy{Name: name, RecoupOwn: true, DenyOthers: true}, nil
+	}
+	return nil, fmt.Errorf("unknown uncle policy %q", name)
+}
+
+// selects up to maxUncles uncles out of candidates for a new block.
+// own reports whether blocks of a miner count as the new block's miner's own, e.g. for members of a coalition.
+// candidates are ordered by depth, most recent first, so the choice does not depend on map order.
+func (p *UnclePolicy) SelectUncles(own func(string) bool, candidates []*Block, maxUncles int) []*Block {
+	sorted := append([]*Block{}, candidates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if p.RecoupOwn && own(a.minerID) != own(b.minerID) {
+			return own(a.minerID)
+		}
+		if a.depth != b.depth {
+			return a.depth > b.depth
+		}
+		return a.GetID() < b.GetID()
+	})
+
+	uncles := []*Block{}
+	for _, u := range sorted {
+		if len(uncles) >= maxUncles {
+			break
+		}
+		if p.DenyOthers && !own(u.minerID) {
+			continue
+		}
+		uncles = append(uncles, u)
+	}
+	return uncles
+}
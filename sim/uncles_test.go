@@ -0,0 +1,60 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectUncles(t *testing.T) {
+	genesis := NewBlock("genesis", nil, nil, 0)
+	main0 := NewBlock("m0", genesis, nil, 10)
+	main1 := NewBlock("m0", main0, nil, 20)
+	//orphans of the selfish miner s and of other miners at depths 0 to 2.
+	blocks := map[string]*Block{
+		"sOld": NewBlock("s", genesis, nil, 11),
+		"mOld": NewBlock("m1", genesis, nil, 12),
+		"sMid": NewBlock("s", main0, nil, 21),
+		"mMid": NewBlock("m2", main0, nil, 22),
+		"mNew": NewBlock("m1", main1, nil, 31),
+	}
+	candidates := []*Block{}
+	for _, name := range []string{"sOld", "mNew", "mOld", "sMid", "mMid"} {
+		candidates = append(candidates, blocks[name])
+	}
+	selfish := func(id string) bool { return id == "s" }
+	coalition := func(id string) bool { return id == "s" || id == "m2" }
+
+	tests := []struct {
+		policy    string
+		own       func(string) bool
+		maxUncles int
+		want      string
+	}{
+		{UNCLES_FRESHEST, selfish, 0, ""},
+		{UNCLES_FRESHEST, selfish, 2, "mNew mMid"},
+		{UNCLES_FRESHEST, selfish, 10, "mNew mMid sMid mOld sOld"},
+		{UNCLES_RECOUP, selfish, 2, "sMid sOld"},        //own orphans first, even the older one
+		{UNCLES_RECOUP, selfish, 3, "sMid sOld mNew"},   //then the freshest of the others
+		{UNCLES_RECOUP, coalition, 3, "mMid sMid sOld"}, //a coalition member's orphan is own too
+		{UNCLES_RECOUP_DENY, selfish, 1, "sMid"},
+		{UNCLES_RECOUP_DENY, selfish, 10, "sMid sOld"}, //never the others' orphans
+		{UNCLES_RECOUP_DENY, coalition, 10, "mMid sMid sOld"},
+	}
+	names := make(map[*Block]string)
+	for name, b := range blocks {
+		names[b] = name
+	}
+	for _, tt := range tests {
+		policy, err := NewUnclePolicy(tt.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, u := range policy.SelectUncles(tt.own, candidates, tt.maxUncles) {
+			got = append(got, names[u])
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s with up to %d uncles: %v, want %s", tt.policy, tt.maxUncles, got, tt.want)
+		}
+	}
+}